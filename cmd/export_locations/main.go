@@ -0,0 +1,66 @@
+// A utility that exports locations, optionally filtered to a date range, to GPX, KML, GeoJSON or CSV.
+// Locations are ordered by time and duplicates are dropped, also when merging multiple inputs into a single timeline.
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/panmari/locationhistory/internal/reader"
+	"github.com/panmari/locationhistory/internal/writer"
+)
+
+var (
+	input  = flag.String("input", "", "Comma separated input files, either .zip, .json, .gpx, .kml or .geojson. Multiple inputs are merged")
+	output = flag.String("output", "", "Output file, the format is chosen based on the extension: .gpx, .kml, .geojson, .csv or .json")
+	from   = flag.String("from", "", "First date to export in the format 2006-01-02, inclusive. Exports from the beginning if empty")
+	to     = flag.String("to", "", "Last date to export in the format 2006-01-02, inclusive. Exports until the end if empty")
+)
+
+// parseDate parses the given date, returning fallback if it's empty.
+func parseDate(date string, fallback time.Time) time.Time {
+	if date == "" {
+		return fallback
+	}
+	t, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		log.Fatalf("Error parsing date %q: %v", date, err)
+	}
+	return t
+}
+
+func main() {
+	flag.Parse()
+
+	// CreateDateFilter excludes both bounds, so widen the range to include the given dates.
+	first := parseDate(*from, time.Time{}).Add(-time.Nanosecond)
+	last := parseDate(*to, time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)).AddDate(0, 0, 1)
+	filter := reader.CreateDateFilter(first, last)
+
+	inputs := strings.Split(*input, ",")
+	sources := make([][]reader.Location, 0, len(inputs))
+	for _, in := range inputs {
+		r, err := reader.OpenFile(in)
+		if err != nil {
+			log.Fatalf("Error when reading %s: %v", in, err)
+		}
+		var locations []reader.Location
+		err = reader.DecodeFunc(in, r, reader.StreamFilterFunc(filter, func(loc reader.Location) error {
+			locations = append(locations, loc)
+			return nil
+		}))
+		if err != nil {
+			log.Fatalf("Error when decoding %s: %v", in, err)
+		}
+		sources = append(sources, locations)
+	}
+	// Merge even a single input, as e.g. Timeline.json is not ordered by time and contains duplicates.
+	locations := reader.Merge(sources...)
+
+	if err := writer.WriteFile(*output, locations); err != nil {
+		log.Fatalf("Error when writing %s: %v", *output, err)
+	}
+	log.Printf("Exported %d locations to %s", len(locations), *output)
+}
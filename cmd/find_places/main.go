@@ -0,0 +1,91 @@
+// A utility that finds significant places by clustering stays and saves them to a places file.
+// Places can be labeled by editing their "name" in the file, labels are kept when running again.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/go-units/unit"
+	"github.com/panmari/locationhistory/internal/geo"
+	"github.com/panmari/locationhistory/internal/processor"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+var (
+	input        = flag.String("input", "", "Input file, either .zip, .json, .gpx, .kml or .geojson")
+	placesFile   = flag.String("places", "places.json", "Places file to write. Names of places already in the file are kept")
+	stayRadius   = flag.Float64("stay-radius", 100, "Radius in meters to stay within for a stay")
+	stayDuration = flag.Duration("stay-duration", 15*time.Minute, "Minimum duration of a stay")
+	epsilon      = flag.Float64("epsilon", 100, "Maximum distance in meters between stays of the same place")
+	minStays     = flag.Int("min-stays", 2, "Minimum number of stays for a place")
+)
+
+// loadPlaces reads previously saved places, if there are any.
+func loadPlaces(filename string) []processor.Place {
+	b, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		log.Fatalf("Error reading places from %s: %v", filename, err)
+	}
+	var places []processor.Place
+	if err := json.Unmarshal(b, &places); err != nil {
+		log.Fatalf("Error decoding places from %s: %v", filename, err)
+	}
+	return places
+}
+
+func main() {
+	flag.Parse()
+
+	r, err := reader.OpenFile(*input)
+	if err != nil {
+		log.Fatalf("Error when reading %s: %v", *input, err)
+	}
+	var locations []reader.Location
+	err = reader.DecodeFunc(*input, r, func(loc reader.Location) error {
+		locations = append(locations, loc)
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Error when decoding %s: %v", *input, err)
+	}
+	// Stay detection requires locations to be ordered by time.
+	locations = reader.Merge(locations)
+
+	stays := processor.StayPoints(locations, processor.StayPointOptions{
+		Radius:      unit.Length(*stayRadius) * unit.Meter,
+		MinDuration: *stayDuration,
+	})
+	places := processor.ClusterPlaces(stays, processor.ClusterOptions{
+		Epsilon:  unit.Length(*epsilon) * unit.Meter,
+		MinStays: *minStays,
+	})
+	processor.KeepNames(places, loadPlaces(*placesFile))
+
+	b, err := json.MarshalIndent(places, "", "  ")
+	if err != nil {
+		log.Fatalf("Error encoding places: %v", err)
+	}
+	if err := os.WriteFile(*placesFile, b, 0o644); err != nil {
+		log.Fatalf("Error writing places to %s: %v", *placesFile, err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tName\tCentroid\tRegion\tVisits\tDwell [h]")
+	for _, p := range places {
+		region, _ := geo.ReverseGeocode(p.Centroid)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%.1f\n", p.ID, p.Name, p.Centroid, region.Code, p.Visits, p.Dwell.Hours())
+	}
+	w.Flush()
+	log.Printf("Found %d places in %d stays, written to %s", len(places), len(stays), *placesFile)
+}
@@ -0,0 +1,111 @@
+// A utility that tracks the days spent in the Schengen area within the rolling 180 day window, warning about periods
+// where the 90 day limit is reached. Planned trips can be added to check whether they stay within the limit.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/panmari/locationhistory/internal/geo"
+	"github.com/panmari/locationhistory/internal/processor"
+	"github.com/panmari/locationhistory/internal/reader"
+	"github.com/panmari/locationhistory/internal/visualizer"
+)
+
+var (
+	input       = flag.String("input", "", "Input file, either .zip, .json, .gpx, .kml or .geojson")
+	plan        = flag.String("plan", "", "Planned stays in the Schengen area in the format from,to:from2,to2, e.g. 2024-06-01,2024-06-30. Both dates are inclusive")
+	timeZone    = flag.String("timezone", "", "Time zone for splitting days. Defaults to the local time where each location was recorded")
+	maxAccuracy = flag.Int("max-accuracy", 0, "Drop locations with an accuracy worse than the given meters. 0 keeps all locations")
+	output      = flag.String("output", "schengen.html", "File for the chart of the remaining allowance")
+)
+
+// plannedDays parses the --plan flag and returns all planned days.
+func plannedDays() []time.Time {
+	if *plan == "" {
+		return nil
+	}
+	var res []time.Time
+	for _, r := range strings.Split(*plan, ":") {
+		from, to, ok := strings.Cut(r, ",")
+		if !ok {
+			log.Fatalf("Error parsing --plan argument %q: expected from,to", r)
+		}
+		first, err := time.Parse(time.DateOnly, from)
+		if err != nil {
+			log.Fatalf("Error parsing --plan argument %q: %v", r, err)
+		}
+		last, err := time.Parse(time.DateOnly, to)
+		if err != nil {
+			log.Fatalf("Error parsing --plan argument %q: %v", r, err)
+		}
+		for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+			res = append(res, day)
+		}
+	}
+	return res
+}
+
+func main() {
+	flag.Parse()
+
+	opts := processor.CountryOptions{}
+	if *timeZone == "" {
+		opts.TimeZoneAt = geo.TimeZone
+	} else {
+		tz, err := time.LoadLocation(*timeZone)
+		if err != nil {
+			log.Fatalf("Error when parsing time zone %q: %v", *timeZone, err)
+		}
+		opts.TimeZone = tz
+	}
+	tracker := processor.NewCountryTracker(opts)
+
+	filter := func(reader.Location) bool { return true }
+	if *maxAccuracy > 0 {
+		filter = reader.CreateAccuracyFilter(*maxAccuracy)
+	}
+	r, err := reader.OpenFile(*input)
+	if err != nil {
+		log.Fatalf("Error when reading %s: %v", *input, err)
+	}
+	err = reader.DecodeFunc(*input, r, reader.StreamFilterFunc(filter, func(loc reader.Location) error {
+		if err := tracker.Add(loc); err != nil {
+			log.Default().Println(err)
+		}
+		return nil
+	}))
+	if err != nil {
+		log.Fatalf("Error when decoding %s: %v", *input, err)
+	}
+
+	allowance := processor.SchengenAllowance(append(processor.SchengenDays(tracker.Days()), plannedDays()...))
+	if len(allowance) == 0 {
+		log.Printf("No days spent in the Schengen area")
+		return
+	}
+	for _, w := range processor.SchengenWarnings(allowance) {
+		problem := "limit of 90 days reached"
+		if w.Overstay() {
+			problem = fmt.Sprintf("limit exceeded by %d days", w.MaxUsed-processor.SchengenMaxDays)
+		}
+		fmt.Printf("Warning: %s to %s: %s\n", w.Start.Format(time.DateOnly), w.End.Format(time.DateOnly), problem)
+	}
+	last := allowance[len(allowance)-1]
+	fmt.Printf("%d of %d days used in the 180 days ending on %s\n", last.Used, processor.SchengenMaxDays, last.Day.Format(time.DateOnly))
+
+	f, err := os.Create(*output)
+	if err != nil {
+		log.Fatalf("Error opening file %s: %v", *output, err)
+	}
+	if err := visualizer.SchengenChart(allowance).Render(f); err != nil {
+		log.Fatalf("Error writing rendering for file %s: %v", *output, err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatalf("Error closing file %s: %v", *output, err)
+	}
+}
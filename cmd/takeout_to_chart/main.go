@@ -6,124 +6,551 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
 	"time"
 	_ "time/tzdata"
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/components"
 	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/golang/geo/s2"
+	"github.com/google/go-units/unit"
+	"github.com/panmari/locationhistory/internal/geo"
 	"github.com/panmari/locationhistory/internal/processor"
 	"github.com/panmari/locationhistory/internal/reader"
 	"github.com/panmari/locationhistory/internal/visualizer"
+	"github.com/panmari/locationhistory/internal/writer"
 )
 
 var (
-	input         = flag.String("input", "", "Input file from google Takeout, either .zip or .json")
-	anchorsString = flag.String("anchors", "", "Anchor location which are used to compute distance. either in the format lat,lng or date,lat,lng:date2,lat,lng")
-	timeZone      = flag.String("timezone", "", "Time zone to use for displaying data")
+	input         = flag.String("input", "", "Input file from google Takeout, either .zip or .json. Timeline.json from on-device exports, .gpx, .kml and .geojson are supported as well")
+	anchorsString = flag.String("anchors", "", "Anchor location which are used to compute distance. either in the format lat,lng or date,lat,lng:date2,lat,lng. Inferred from the nights spent if empty")
+	anchorsFile   = flag.String("anchors-file", "", "JSON file with named anchors, see README.md for the format. Takes precedence over --anchors")
+	timeZone      = flag.String("timezone", "", "Time zone to use for displaying data and inferring anchors, defaults to Europe/Zurich. Anchors with their own time zone use that instead")
+	localTime     = flag.Bool("local-time", false, "Use the local time where each location was recorded for charts, e.g. for showing nights during a trip abroad. Time zones are looked up offline from the coordinates")
+	semantic      = flag.Bool("semantic", false, "Use visits and activities from Semantic Location History instead of raw records")
+	maxAccuracy   = flag.Int("max-accuracy", 0, "Drop locations with an accuracy worse than the given meters. 0 keeps all locations")
+	sources       = flag.String("sources", "", "Comma separated sources of locations to keep, e.g. GPS,WIFI. Keeps all locations if empty")
+	maxSpeed      = flag.Float64("max-speed", 0, "Drop outliers that imply a speed higher than the given km/h. 0 keeps all locations")
+	tripDistance  = flag.Float64("trip-distance", 50, "Minimum distance in km from the anchor for a day to count as part of a trip")
+	emissions     = flag.String("emission-factors", "", "Grams of CO2 per km and mode of transport overriding the defaults, in the format mode=grams,mode2=grams, e.g. driving=120,flight=200")
+	devicesString = flag.String("devices", "", "Only use locations of the primary device, either in the format deviceTag or date,deviceTag:date2,deviceTag2. Use 'auto' for choosing the device with the most locations per month")
 )
 
-func yearlyCharts(anchors []processor.Anchor, decoded []reader.Location) *components.Page {
-	page := components.NewPage()
-	page.PageTitle = "Yearly plots from timeline"
-	bucketOpts := processor.Options{Anchors: anchors, BucketDuration: time.Hour * 24, Reducer: processor.MaxDistance}
-	for year := 2014; year < 2024; year++ {
-		first, _ := time.Parse(time.DateOnly, fmt.Sprintf("%d-01-01", year))
-		last, _ := time.Parse(time.DateOnly, fmt.Sprintf("%d-12-31", year))
+// newDistanceBucketer creates a bucketer of the farthest distance from the nearest anchor per bucket of the given
+// duration. Buckets are split according to the time zone of the nearest anchor, falling back to tz for anchors without
+// time zone, or the local time zone of each location if timeZoneAt is set.
+func newDistanceBucketer(anchors []processor.Anchor, bucketDuration time.Duration, tz *time.Location, timeZoneAt processor.ZoneFunc) *processor.Bucketer {
+	return processor.NewBucketer(processor.Options{Anchors: anchors, BucketDuration: bucketDuration, Reducer: processor.MaxDistance, TimeZone: tz, TimeZoneAt: timeZoneAt})
+}
 
-		filter := reader.CreateDateFilter(first, last)
-		locations, err := reader.FilterFunc(decoded, filter)
-		if err != nil {
-			log.Fatalf("Error when filtering for %d: %v", year, err)
+// locationZone is the time zone of the location currently passed on to the aggregators. It is looked up once per
+// location, instead of by every aggregator.
+type locationZone struct {
+	latlng s2.LatLng
+	zone   *time.Location
+}
+
+// set looks up the time zone of the given location.
+func (z *locationZone) set(loc reader.Location) {
+	z.latlng = loc.LatLng()
+	z.zone = geo.TimeZone(z.latlng)
+}
+
+// at returns the time zone at the given coordinates, using the zone of the current location if they match.
+func (z *locationZone) at(ll s2.LatLng) *time.Location {
+	if z.zone != nil && ll == z.latlng {
+		return z.zone
+	}
+	return geo.TimeZone(ll)
+}
+
+// bucketYears returns the years of the given buckets, in ascending order.
+func bucketYears(buckets ...[]processor.DistanceByTimeBucket) []int {
+	var res []int
+	for _, items := range buckets {
+		for _, d := range items {
+			if !slices.Contains(res, d.Bucket.Year()) {
+				res = append(res, d.Bucket.Year())
+			}
 		}
-		if len(locations) == 0 {
-			continue
+	}
+	slices.Sort(res)
+	return res
+}
+
+// inYear returns the items in the given year, according to the time of their bucket.
+func inYear[T any](items []T, year int, bucket func(T) time.Time) []T {
+	var res []T
+	for _, item := range items {
+		if bucket(item).Year() == year {
+			res = append(res, item)
 		}
-		maxDist, err := processor.TimeBucketDistance(locations, bucketOpts)
-		if err != nil {
-			log.Fatalf("Error when bucketing for %d: %v", year, err)
-		}
-		for i, res := range [][]processor.DistanceByTimeBucket{maxDist} {
-			bar := visualizer.BarChart(res)
-			bar.SetGlobalOptions(
-				charts.WithTitleOpts(opts.Title{
-					Title: fmt.Sprintf("Year: %d, %d", year, i),
-				}),
-			)
+	}
+	return res
+}
+
+// distanceBucket returns the start of the bucket of d.
+func distanceBucket(d processor.DistanceByTimeBucket) time.Time {
+	return d.Bucket
+}
+
+// modeBucket returns the start of the bucket of m.
+func modeBucket(m processor.ModeByTimeBucket) time.Time {
+	return m.Bucket
+}
+
+// yearlyCharts creates the charts of every year with data, from the given daily buckets of the distance from the
+// anchors, the distance traveled and the distance traveled per mode of transport.
+func yearlyCharts(daily, pathLengths []processor.DistanceByTimeBucket, modes []processor.ModeByTimeBucket) *components.Page {
+	page := components.NewPage()
+	page.PageTitle = "Yearly plots from timeline"
+	for _, year := range bucketYears(daily, pathLengths) {
+		// Days without active anchor have no distance from it, but the distance traveled is still charted.
+		if maxDist := inYear(daily, year, distanceBucket); len(maxDist) > 0 {
+			for i, res := range [][]processor.DistanceByTimeBucket{maxDist} {
+				bar := visualizer.BarChart(res)
+				bar.SetGlobalOptions(
+					charts.WithTitleOpts(opts.Title{
+						Title:    fmt.Sprintf("Year: %d, %d", year, i),
+						Subtitle: anchorNames(res),
+					}),
+				)
+				page.AddCharts(bar)
+				page.AddCharts(visualizer.Heatmap(res))
+			}
+		}
+		traveled := inYear(pathLengths, year, distanceBucket)
+		for _, res := range []struct {
+			name    string
+			buckets []processor.DistanceByTimeBucket
+		}{{"day", traveled}, {"week", processor.SumWeekly(traveled)}} {
+			bar := visualizer.PathLengthChart(res.buckets)
+			bar.SetGlobalOptions(charts.WithTitleOpts(opts.Title{Title: fmt.Sprintf("Year: %d, distance traveled per %s", year, res.name)}))
 			page.AddCharts(bar)
-			page.AddCharts(visualizer.Heatmap(res))
+		}
+		if modes := processor.SumModesMonthly(inYear(modes, year, modeBucket)); len(modes) > 0 {
+			distance := visualizer.ModeDistanceChart(modes)
+			distance.SetGlobalOptions(charts.WithTitleOpts(opts.Title{Title: fmt.Sprintf("Year: %d, distance per mode of transport", year)}))
+			duration := visualizer.ModeDurationChart(modes)
+			duration.SetGlobalOptions(charts.WithTitleOpts(opts.Title{Title: fmt.Sprintf("Year: %d, time per mode of transport", year)}))
+			page.AddCharts(distance, duration)
 		}
 	}
 	return page
 }
 
-func dailyCharts(anchors []processor.Anchor, decoded []reader.Location) *components.Page {
+// printPathLengths prints the distance traveled per year to stdout, in total and per mode of transport according to
+// the given daily buckets.
+func printPathLengths(pathLengths []processor.DistanceByTimeBucket, modes []processor.ModeByTimeBucket) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Year\tDistance traveled [km]\t")
+	for _, b := range processor.SumYearly(pathLengths) {
+		fmt.Fprintf(w, "%d\t%.0f\t\n", b.Bucket.Year(), b.Distance.Kilometers())
+	}
+	w.Flush()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Year\tMode\tDistance [km]\tTime [h]\t")
+	for _, m := range processor.SumModesYearly(modes) {
+		fmt.Fprintf(w, "%d\t%s\t%.0f\t%.1f\t\n", m.Bucket.Year(), m.Mode, m.Distance.Kilometers(), m.Duration.Hours())
+	}
+	w.Flush()
+}
+
+// anchorNames returns the distinct names of the anchors used for the given buckets, in order of appearance.
+func anchorNames(items []processor.DistanceByTimeBucket) string {
+	var names []string
+	for _, d := range items {
+		if d.Anchor != "" && !slices.Contains(names, d.Anchor) {
+			names = append(names, d.Anchor)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// dailyCharts creates radar charts of the given hourly buckets of the distance from the anchors for every year.
+func dailyCharts(hourly []processor.DistanceByTimeBucket) *components.Page {
 	page := components.NewPage().SetLayout(components.PageFlexLayout)
 	page.PageTitle = "Daily plots from timeline"
-	// TODO(panmari): Move concept of timezone to anchor, so far moves are easier to account for.
-	tz, err := time.LoadLocation("Europe/Zurich")
-	if err != nil {
-		log.Fatalf("Error when parsing time zone %q: %v", *timeZone, err)
+	for _, year := range bucketYears(hourly) {
+		radars := visualizer.DailyRadar(inYear(hourly, year, distanceBucket), visualizer.Options{Title: fmt.Sprintf("Year %d", year)})
+		page.AddCharts(radars...)
+
 	}
-	bucketOpts := processor.Options{Anchors: anchors, BucketDuration: time.Hour, Reducer: processor.MaxDistance}
-	for year := 2014; year < 2024; year++ {
-		first, _ := time.Parse(time.DateOnly, fmt.Sprintf("%d-01-01", year))
-		last, _ := time.Parse(time.DateOnly, fmt.Sprintf("%d-12-31", year))
+	return page
+}
 
-		filter := reader.CreateDateFilter(first, last)
-		locations, err := reader.FilterFunc(decoded, filter)
+// decodeInput calls fn for every location of the input, either from raw records or Semantic Location History.
+func decodeInput(fn func(reader.Location) error) {
+	if *semantic {
+		history, err := reader.OpenSemanticFiles(*input)
 		if err != nil {
-			log.Fatalf("Error when filtering for %d: %v", year, err)
+			log.Fatalf("Error when reading semantic location history from %s: %v", *input, err)
 		}
-		if len(locations) == 0 {
-			continue
+		for _, loc := range history.Locations() {
+			if err := fn(loc); err != nil {
+				log.Fatalf("Error when processing %s: %v", *input, err)
+			}
 		}
-		maxDist, err := processor.TimeBucketDistance(locations, bucketOpts)
+		return
+	}
+	r, err := reader.OpenFile(*input)
+	if err != nil {
+		log.Fatalf("Error when reading %s: %v", *input, err)
+	}
+	// Stream locations ordered by time, so the whole Records.json export never has to be held in memory. Other
+	// formats are sorted by DecodeFunc, as path lengths, modes of transport, flights and --max-speed depend on the order.
+	if err := reader.DecodeFunc(*input, r, fn); err != nil {
+		log.Fatalf("Error when decoding %s: %v", *input, err)
+	}
+}
+
+// deviceFilter creates a filter according to the --devices flag.
+func deviceFilter() func(reader.Location) bool {
+	if *devicesString == "" {
+		return func(reader.Location) bool { return true }
+	}
+	if *devicesString != "auto" {
+		ranges, err := reader.ParseDeviceRanges(*devicesString)
 		if err != nil {
-			log.Fatalf("Error when bucketing for %d: %v", year, err)
+			log.Fatalf("Error parsing --devices argument %q: %v", *devicesString, err)
 		}
-		radars := visualizer.DailyRadar(maxDist, visualizer.Options{Title: fmt.Sprintf("Year %d", year), TimeZone: tz})
-		page.AddCharts(radars...)
+		return reader.CreateDeviceFilter(ranges)
+	}
+	// Requires an additional pass over the input for counting locations per device.
+	counter := reader.NewDeviceCounter(reader.MonthStart)
+	decodeInput(func(loc reader.Location) error {
+		if err := counter.Add(loc); err != nil {
+			log.Default().Println(err)
+		}
+		return nil
+	})
+	ranges := counter.PrimaryDevices()
+	for _, r := range ranges {
+		log.Printf("Using device %d starting at %s", r.DeviceTag, r.StartTime.Format(time.DateOnly))
+	}
+	return reader.CreateDeviceFilter(ranges)
+}
 
+// qualityFilter creates a filter according to the --max-accuracy and --sources flags.
+func qualityFilter() func(reader.Location) bool {
+	accuracyFilter := func(reader.Location) bool { return true }
+	if *maxAccuracy > 0 {
+		accuracyFilter = reader.CreateAccuracyFilter(*maxAccuracy)
+	}
+	sourceFilter := func(reader.Location) bool { return true }
+	if *sources != "" {
+		sourceFilter = reader.CreateSourceFilter(strings.Split(*sources, ",")...)
+	}
+	return func(loc reader.Location) bool {
+		return accuracyFilter(loc) && sourceFilter(loc)
 	}
-	return page
 }
 
-func main() {
-	flag.Parse()
+// printTrips prints the given trips as table to stdout, with the CO2 emitted according to the daily buckets per
+// mode of transport.
+func printTrips(trips []processor.Trip, modes []processor.ModeByTimeBucket, factors processor.EmissionFactors) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Start\tEnd\tDays\tMax distance [km]\tFarthest point\tPath length [km]\tCO2 [kg]\t")
+	for _, t := range trips {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%.1f\t%s\t%.1f\t%.0f\t\n",
+			t.Start.Format(time.DateOnly), t.End.Format(time.DateOnly), t.Days(),
+			t.MaxDistance.Kilometers(), t.Farthest, t.PathLength.Kilometers(), factors.TripKilograms(t, modes))
+	}
+	w.Flush()
+}
 
-	anchors, err := processor.ParseAnchors(*anchorsString)
-	if err != nil {
-		log.Fatalf("Error parsing --anchors argument %q: %v", *anchorsString, err)
+// printFlights prints the given flights as table to stdout, with departure and arrival in local time.
+func printFlights(flights []processor.FlightLeg) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Date\tDeparture\tArrival\tRoute\tFrom\tTo\tDistance [km]")
+	for _, f := range flights {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%.0f\n",
+			f.Departure.Format(time.DateOnly), f.Departure.Format("15:04"), f.Arrival.Format("15:04"),
+			f.Route(), f.From.Name, f.To.Name, f.Distance.Kilometers())
 	}
+	w.Flush()
+}
 
-	r, err := reader.OpenFile(*input)
-	if err != nil {
-		log.Fatalf("Error when reading %s: %v", *input, err)
+// printEmissions prints the CO2 emitted per month and year to stdout, per mode of transport with emissions and in
+// total.
+func printEmissions(modes []processor.ModeByTimeBucket, factors processor.EmissionFactors) {
+	var emitting []processor.Mode
+	for _, mode := range processor.Modes {
+		if factors[mode] > 0 {
+			emitting = append(emitting, mode)
+		}
 	}
-	decoded, err := reader.DecodeJson(r)
-	if err != nil {
-		log.Fatalf("Error when decoding %s: %v", *input, err)
+	for _, period := range []struct {
+		name    string
+		format  string
+		buckets []processor.ModeByTimeBucket
+	}{{"Month", "2006-01", processor.SumModesMonthly(modes)}, {"Year", "2006", processor.SumModesYearly(modes)}} {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintf(w, "%s\t", period.name)
+		for _, mode := range emitting {
+			fmt.Fprintf(w, "%s [kg CO2]\t", mode)
+		}
+		fmt.Fprintln(w, "Total [kg CO2]\t")
+		for i := 0; i < len(period.buckets); {
+			j := i
+			byMode := make(map[processor.Mode]float64)
+			for j < len(period.buckets) && period.buckets[j].Bucket.Format(time.DateOnly) == period.buckets[i].Bucket.Format(time.DateOnly) {
+				byMode[period.buckets[j].Mode] = factors.Kilograms(period.buckets[j])
+				j++
+			}
+			fmt.Fprintf(w, "%s\t", period.buckets[i].Bucket.Format(period.format))
+			for _, mode := range emitting {
+				fmt.Fprintf(w, "%.0f\t", byMode[mode])
+			}
+			fmt.Fprintf(w, "%.0f\t\n", factors.TotalKilograms(period.buckets[i:j]))
+			i = j
+		}
+		w.Flush()
+	}
+}
+
+// emissionCharts creates a chart of the CO2 emitted per year, followed by the emissions per month for every year.
+func emissionCharts(modes []processor.ModeByTimeBucket, factors processor.EmissionFactors) *components.Page {
+	page := components.NewPage()
+	page.PageTitle = "CO2 emissions"
+	if len(modes) == 0 {
+		return page
+	}
+	bar := visualizer.EmissionsChart(processor.SumModesYearly(modes), factors)
+	bar.SetGlobalOptions(charts.WithTitleOpts(opts.Title{Title: "CO2 emissions per year"}))
+	page.AddCharts(bar)
+	monthly := processor.SumModesMonthly(modes)
+	for i := 0; i < len(monthly); {
+		j := i
+		for j < len(monthly) && monthly[j].Bucket.Year() == monthly[i].Bucket.Year() {
+			j++
+		}
+		bar := visualizer.EmissionsChart(monthly[i:j], factors)
+		bar.SetGlobalOptions(charts.WithTitleOpts(opts.Title{Title: fmt.Sprintf("Year: %d, CO2 emissions per month", monthly[i].Bucket.Year())}))
+		page.AddCharts(bar)
+		i = j
+	}
+	return page
+}
+
+// printCountries prints the countries visited per year to stdout, with the number of days spent in each.
+func printCountries(years []processor.CountryYear) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Year\tCountries\tDays per country")
+	for _, y := range years {
+		visits := make([]string, 0, len(y.Countries))
+		for _, c := range y.Countries {
+			visits = append(visits, fmt.Sprintf("%s: %d", c.Country, c.Days))
+		}
+		fmt.Fprintf(w, "%d\t%d\t%s\n", y.Year, len(y.Countries), strings.Join(visits, ", "))
+	}
+	w.Flush()
+}
+
+// countryCharts creates a timeline of the countries visited for every year.
+func countryCharts(days []processor.CountryDay) *components.Page {
+	page := components.NewPage()
+	page.PageTitle = "Countries visited"
+	for i := 0; i < len(days); {
+		j := i
+		for j < len(days) && days[j].Day.Year() == days[i].Day.Year() {
+			j++
+		}
+		timeline := visualizer.CountryTimeline(days[i:j])
+		timeline.SetGlobalOptions(charts.WithTitleOpts(opts.Title{Title: fmt.Sprintf("Year: %d", days[i].Day.Year())}))
+		page.AddCharts(timeline)
+		i = j
 	}
+	return page
+}
 
-	yearlyPage := yearlyCharts(anchors, decoded)
-	filename := fmt.Sprintf("yearly.html")
+// renderFile renders the given page to a new file with the given name.
+func renderFile(filename string, page *components.Page) {
 	f, err := os.Create(filename)
 	if err != nil {
 		log.Fatalf("Error opening file %s: %v", filename, err)
 	}
-	if yearlyPage.Render(f); err != nil {
+	if err := page.Render(f); err != nil {
 		log.Fatalf("Error writing rendering for file %s: %v", filename, err)
 	}
+	if err := f.Close(); err != nil {
+		log.Fatalf("Error closing file %s: %v", filename, err)
+	}
+}
+
+// decodeFiltered calls fn for every location of the input that passes the given filter and, if --max-speed is set,
+// is not an outlier.
+func decodeFiltered(filter func(reader.Location) bool, fn func(reader.Location) error) {
+	flush := func() error { return nil }
+	if *maxSpeed > 0 {
+		fn, flush = reader.StreamTeleportFilterFunc(unit.Speed(*maxSpeed)*unit.KilometerPerHour, fn)
+	}
+	decodeInput(reader.StreamFilterFunc(filter, fn))
+	if err := flush(); err != nil {
+		log.Fatalf("Error when processing %s: %v", *input, err)
+	}
+}
 
-	dailyPage := dailyCharts(anchors, decoded)
-	filename = fmt.Sprintf("daily.html")
-	f, err = os.Create(filename)
+// loadTimeZone loads the time zone given by --timezone, defaulting to Europe/Zurich.
+func loadTimeZone() *time.Location {
+	name := *timeZone
+	if name == "" {
+		name = "Europe/Zurich"
+	}
+	tz, err := time.LoadLocation(name)
 	if err != nil {
-		log.Fatalf("Error opening file %s: %v", filename, err)
+		log.Fatalf("Error when parsing time zone %q: %v", name, err)
 	}
-	if dailyPage.Render(f); err != nil {
-		log.Fatalf("Error writing rendering for file %s: %v", filename, err)
+	return tz
+}
+
+// loadAnchors reads the anchors from --anchors-file, parses the --anchors flag or, if both are empty, infers home
+// anchors from the input.
+func loadAnchors(filter func(reader.Location) bool) []processor.Anchor {
+	if *anchorsFile != "" {
+		f, err := os.Open(*anchorsFile)
+		if err != nil {
+			log.Fatalf("Error opening anchors file %s: %v", *anchorsFile, err)
+		}
+		defer f.Close()
+		anchors, err := processor.LoadAnchors(f)
+		if err != nil {
+			log.Fatalf("Error reading anchors from %s: %v", *anchorsFile, err)
+		}
+		if len(anchors) == 0 {
+			log.Fatalf("No anchors found in %s", *anchorsFile)
+		}
+		return anchors
+	}
+	if *anchorsString != "" {
+		anchors, err := processor.ParseAnchors(*anchorsString)
+		if err != nil {
+			log.Fatalf("Error parsing --anchors argument %q: %v", *anchorsString, err)
+		}
+		return anchors
+	}
+	// Requires an additional pass over the input.
+	inferrer := processor.NewAnchorInferrer(processor.InferOptions{
+		Window:     time.Hour * 24 * 30,
+		MinWindows: 2,
+		CellLevel:  15,
+		TimeZone:   loadTimeZone(),
+	})
+	decodeFiltered(filter, func(loc reader.Location) error {
+		if err := inferrer.Add(loc); err != nil {
+			log.Default().Println(err)
+		}
+		return nil
+	})
+	home := inferrer.Home()
+	if len(home) == 0 {
+		log.Fatalf("Could not infer home from %s, please pass --anchors", *input)
+	}
+	// Home and work are used simultaneously, distances are measured to the nearest of both.
+	var anchors []processor.Anchor
+	for _, a := range home {
+		log.Printf("Inferred home at %s starting at %s", a.Location, a.StartTime.Format(time.DateOnly))
+		a.Name = "home"
+		anchors = append(anchors, a)
+	}
+	for _, a := range inferrer.Work() {
+		log.Printf("Inferred work at %s starting at %s", a.Location, a.StartTime.Format(time.DateOnly))
+		a.Name = "work"
+		anchors = append(anchors, a)
+	}
+	slices.SortStableFunc(anchors, func(a, b processor.Anchor) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
+	return anchors
+}
+
+func main() {
+	flag.Parse()
+
+	factors, err := processor.ParseEmissionFactors(*emissions)
+	if err != nil {
+		log.Fatalf("Error parsing --emission-factors argument: %v", err)
+	}
+	deviceFilter, qualityFilter := deviceFilter(), qualityFilter()
+	filter := func(loc reader.Location) bool {
+		return deviceFilter(loc) && qualityFilter(loc)
 	}
+	anchors := loadAnchors(filter)
+
+	tz := loadTimeZone()
+	var zone locationZone
+	var timeZoneAt processor.ZoneFunc
+	if *localTime {
+		timeZoneAt = zone.at
+	}
+	daily := newDistanceBucketer(anchors, time.Hour*24, tz, timeZoneAt)
+	hourly := newDistanceBucketer(anchors, time.Hour, tz, timeZoneAt)
+	// Every aggregate sees all locations, so charts and tables cover the same days, whichever years the input spans.
+	pathLengthBucketer := processor.NewPathLengthBucketer(processor.PathLengthOptions{TimeZone: tz, TimeZoneAt: timeZoneAt})
+	modeBucketer := processor.NewModeBucketer(processor.ModeOptions{TimeZone: tz, TimeZoneAt: timeZoneAt})
+	activityBucketer := processor.NewActivityBucketer(processor.ActivityOptions{TimeZone: tz, TimeZoneAt: timeZoneAt})
+	tripDetector := processor.NewTripDetector(processor.TripOptions{
+		Anchors:     anchors,
+		MinDistance: unit.Length(*tripDistance) * unit.Kilometer,
+		TimeZone:    tz,
+	})
+	countryTracker := processor.NewCountryTracker(processor.CountryOptions{TimeZone: tz, TimeZoneAt: timeZoneAt})
+	flightDetector := processor.NewFlightDetector(processor.FlightOptions{TimeZoneAt: geo.TimeZone})
+	decodeFiltered(filter, func(loc reader.Location) error {
+		if *localTime {
+			zone.set(loc)
+		}
+		if err := daily.Add(loc); err != nil {
+			log.Default().Println(err)
+		}
+		if err := hourly.Add(loc); err != nil {
+			log.Default().Println(err)
+		}
+		if err := pathLengthBucketer.Add(loc); err != nil {
+			log.Default().Println(err)
+		}
+		if err := modeBucketer.Add(loc); err != nil {
+			log.Default().Println(err)
+		}
+		if err := activityBucketer.Add(loc); err != nil {
+			log.Default().Println(err)
+		}
+		if err := tripDetector.Add(loc); err != nil {
+			log.Default().Println(err)
+		}
+		if err := countryTracker.Add(loc); err != nil {
+			log.Default().Println(err)
+		}
+		if err := flightDetector.Add(loc); err != nil {
+			log.Default().Println(err)
+		}
+		return nil
+	})
+
+	pathLengths, modes := pathLengthBucketer.Buckets(), modeBucketer.Buckets()
+	trips := tripDetector.Trips()
+	printTrips(trips, modes, factors)
+	for _, filename := range []string{"trips.csv", "trips.json"} {
+		if err := writer.WriteTripsFile(filename, trips); err != nil {
+			log.Fatalf("Error writing trips to %s: %v", filename, err)
+		}
+	}
+
+	if err := writer.WriteActivitiesFile("activities.csv", activityBucketer.Durations()); err != nil {
+		log.Fatalf("Error writing activities to activities.csv: %v", err)
+	}
+
+	printFlights(flightDetector.Flights())
+	printPathLengths(pathLengths, modes)
+	printEmissions(modes, factors)
+	countryDays := countryTracker.Days()
+	printCountries(processor.CountriesByYear(countryDays))
 
+	renderFile("yearly.html", yearlyCharts(daily.Buckets(), pathLengths, modes))
+	renderFile("emissions.html", emissionCharts(modes, factors))
+	renderFile("daily.html", dailyCharts(hourly.Buckets()))
+	renderFile("countries.html", countryCharts(countryDays))
 }
@@ -0,0 +1,107 @@
+// A utility that counts the days spent in each country per calendar or fiscal year, e.g. for tax or visa paperwork.
+// Besides the table of days per country, an audit trail lists the locations supporting each counted day.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/panmari/locationhistory/internal/geo"
+	"github.com/panmari/locationhistory/internal/processor"
+	"github.com/panmari/locationhistory/internal/reader"
+	"github.com/panmari/locationhistory/internal/writer"
+)
+
+var (
+	input       = flag.String("input", "", "Input file, either .zip, .json, .gpx, .kml or .geojson")
+	rule        = flag.String("rule", "any", "Rule for counting days, either 'any' for counting every country with a location on a day, or 'midnight' for counting only the country where midnight was spent")
+	yearStart   = flag.String("year-start", "01-01", "First day of the fiscal year in the format MM-DD, e.g. 04-06 for the UK")
+	timeZone    = flag.String("timezone", "", "Time zone for splitting days. Defaults to the local time where each location was recorded")
+	maxAccuracy = flag.Int("max-accuracy", 0, "Drop locations with an accuracy worse than the given meters, e.g. to avoid counting a country across the border. 0 keeps all locations")
+	maxCarry    = flag.Int("max-carry-days", 7, "With --rule=midnight, the maximum number of days without locations that count for the country of the last location before them")
+	audit       = flag.String("audit", "residency_audit.csv", "File for the audit trail of every counted day, either .csv or .json")
+)
+
+// parseRule parses the --rule flag.
+func parseRule() processor.ResidencyRule {
+	switch *rule {
+	case "any":
+		return processor.AnyPresence
+	case "midnight":
+		return processor.Midnight
+	}
+	log.Fatalf("Error parsing --rule argument %q: must be any or midnight", *rule)
+	return 0
+}
+
+// parseYearStart parses the --year-start flag.
+func parseYearStart() (time.Month, int) {
+	t, err := time.Parse("01-02", *yearStart)
+	if err != nil {
+		log.Fatalf("Error parsing --year-start argument %q: %v", *yearStart, err)
+	}
+	return t.Month(), t.Day()
+}
+
+func main() {
+	flag.Parse()
+
+	month, day := parseYearStart()
+	opts := processor.ResidencyOptions{
+		Rule:           parseRule(),
+		MaxCarryDays:   *maxCarry,
+		YearStartMonth: month,
+		YearStartDay:   day,
+	}
+	if *timeZone == "" {
+		opts.TimeZoneAt = geo.TimeZone
+	} else {
+		tz, err := time.LoadLocation(*timeZone)
+		if err != nil {
+			log.Fatalf("Error when parsing time zone %q: %v", *timeZone, err)
+		}
+		opts.TimeZone = tz
+	}
+	if opts.MaxCarryDays == 0 {
+		// Zero options are replaced by defaults, negative values disable carrying days over.
+		opts.MaxCarryDays = -1
+	}
+	counter := processor.NewResidencyCounter(opts)
+
+	filter := func(reader.Location) bool { return true }
+	if *maxAccuracy > 0 {
+		filter = reader.CreateAccuracyFilter(*maxAccuracy)
+	}
+	r, err := reader.OpenFile(*input)
+	if err != nil {
+		log.Fatalf("Error when reading %s: %v", *input, err)
+	}
+	err = reader.DecodeFunc(*input, r, reader.StreamFilterFunc(filter, func(loc reader.Location) error {
+		if err := counter.Add(loc); err != nil {
+			log.Default().Println(err)
+		}
+		return nil
+	}))
+	if err != nil {
+		log.Fatalf("Error when decoding %s: %v", *input, err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Start\tEnd\tCountry\tDays")
+	for _, y := range counter.Years() {
+		for _, c := range y.Countries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", y.Start.Format(time.DateOnly), y.End.Format(time.DateOnly), c.Country, c.Days)
+		}
+	}
+	w.Flush()
+
+	days := counter.Days()
+	if err := writer.WriteResidencyFile(*audit, days); err != nil {
+		log.Fatalf("Error writing audit trail to %s: %v", *audit, err)
+	}
+	log.Printf("Wrote audit trail of %d days to %s", len(days), *audit)
+}
@@ -0,0 +1,77 @@
+package geo
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/golang/geo/earth"
+	"github.com/golang/geo/s2"
+	"github.com/google/go-units/unit"
+)
+
+//go:embed data/airports.csv
+var airportData []byte
+
+var airports = sync.OnceValues(func() ([]Airport, error) {
+	return decodeAirports(airportData)
+})
+
+// Airport is a commercial airport.
+type Airport struct {
+	// IATA is the three letter code of the airport, e.g. ZRH.
+	IATA string
+	// Country is the ISO 3166-1 alpha-2 code of the country, e.g. CH.
+	Country string
+	// Name of the airport, e.g. Zurich.
+	Name   string
+	LatLng s2.LatLng
+}
+
+// decodeAirports decodes airports from CSV with the columns iata, country, name, lat and lng.
+func decodeAirports(data []byte) ([]Airport, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("missing header")
+	}
+	res := make([]Airport, 0, len(records)-1)
+	for _, r := range records[1:] {
+		if len(r) != 5 {
+			return nil, fmt.Errorf("expected 5 columns, got %v", r)
+		}
+		lat, err := strconv.ParseFloat(r[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid latitude of %s: %w", r[0], err)
+		}
+		lng, err := strconv.ParseFloat(r[4], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid longitude of %s: %w", r[0], err)
+		}
+		res = append(res, Airport{IATA: r[0], Country: r[1], Name: r[2], LatLng: s2.LatLngFromDegrees(lat, lng)})
+	}
+	return res, nil
+}
+
+// NearestAirport returns the embedded airport nearest to the given coordinates, unless it is further away than
+// maxDistance. Only large airports are embedded, see data/README.md. Panics if the embedded data is invalid.
+func NearestAirport(ll s2.LatLng, maxDistance unit.Length) (Airport, bool) {
+	all, err := airports()
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded airports: %v", err))
+	}
+	var nearest Airport
+	minDistance := earth.AngleFromLength(maxDistance)
+	found := false
+	for _, a := range all {
+		if d := a.LatLng.Distance(ll); d <= minDistance {
+			nearest, minDistance, found = a, d, true
+		}
+	}
+	return nearest, found
+}
@@ -0,0 +1,47 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/go-units/unit"
+)
+
+func TestNearestAirport(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		ll     s2.LatLng
+		want   string
+		wantOk bool
+	}{
+		{name: "Zurich main station", ll: s2.LatLngFromDegrees(47.3779, 8.5403), want: "ZRH", wantOk: true},
+		{name: "Terminal at Heathrow", ll: s2.LatLngFromDegrees(51.4700, -0.4543), want: "LHR", wantOk: true},
+		{name: "Closer to Haneda than Narita", ll: s2.LatLngFromDegrees(35.6762, 139.6503), want: "HND", wantOk: true},
+		{name: "Sydney", ll: s2.LatLngFromDegrees(-33.8688, 151.2093), want: "SYD", wantOk: true},
+		{name: "Atlantic", ll: s2.LatLngFromDegrees(30, -40)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := NearestAirport(tc.ll, 50*unit.Kilometer)
+			if ok != tc.wantOk || got.IATA != tc.want {
+				t.Errorf("NearestAirport(%v) = %v, %t, want %s, %t", tc.ll, got.IATA, ok, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestEmbeddedAirports(t *testing.T) {
+	all, err := airports()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for _, a := range all {
+		if len(a.IATA) != 3 || len(a.Country) != 2 || !a.LatLng.IsValid() {
+			t.Errorf("invalid airport %+v", a)
+		}
+		if seen[a.IATA] {
+			t.Errorf("duplicate airport %s", a.IATA)
+		}
+		seen[a.IATA] = true
+	}
+}
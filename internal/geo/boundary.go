@@ -0,0 +1,91 @@
+// Package geo provides offline lookups of time zones, countries and regions from coordinates, based on simplified
+// boundaries embedded in the binary. Boundaries are generated with ./gen, see data/README.md for their sources.
+package geo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+
+	"github.com/golang/geo/earth"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"github.com/google/go-units/unit"
+)
+
+// area is a polygon together with the properties of its boundary, e.g. the name of a time zone.
+type area struct {
+	*s2.Polygon
+	properties map[string]string
+}
+
+// boundaries is a set of areas, indexed for looking up the area containing a point.
+type boundaries struct {
+	index *s2.ShapeIndex
+}
+
+// decodeBoundaries reads boundaries in the format written by ./gen: gzipped JSON with counterclockwise loops of
+// lat,lng pairs in 1e-4 degrees, where all but the first pair are stored as difference to the previous pair.
+func decodeBoundaries(data []byte) (*boundaries, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Boundaries []struct {
+			Properties map[string]string `json:"properties"`
+			Loops      [][]int32         `json:"loops"`
+		} `json:"boundaries"`
+	}
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("decoding boundaries: %w", err)
+	}
+	index := s2.NewShapeIndex()
+	for _, b := range file.Boundaries {
+		loops := make([]*s2.Loop, 0, len(b.Loops))
+		for _, l := range b.Loops {
+			if loop := decodeLoop(l); loop != nil {
+				loops = append(loops, loop)
+			}
+		}
+		index.Add(&area{Polygon: s2.PolygonFromLoops(loops), properties: b.Properties})
+	}
+	return &boundaries{index: index}, nil
+}
+
+// decodeLoop converts delta encoded coordinates to a loop. Returns nil if the loop is degenerate, i.e. s2 considers
+// it to cover more than half of the sphere.
+func decodeLoop(coords []int32) *s2.Loop {
+	points := make([]s2.Point, 0, len(coords)/2)
+	var lat, lng int32
+	for i := 0; i+1 < len(coords); i += 2 {
+		if i == 0 {
+			lat, lng = coords[0], coords[1]
+		} else {
+			lat, lng = lat+coords[i], lng+coords[i+1]
+		}
+		points = append(points, s2.PointFromLatLng(s2.LatLngFromDegrees(float64(lat)/1e4, float64(lng)/1e4)))
+	}
+	loop := s2.LoopFromPoints(points)
+	if !loop.IsNormalized() {
+		return nil
+	}
+	return loop
+}
+
+// lookup returns the area containing the given point. Simplified boundaries leave small gaps between neighbouring
+// areas, so if no area contains the point, the nearest one within maxDistance is returned.
+func (b *boundaries) lookup(ll s2.LatLng, maxDistance unit.Length) (*area, bool) {
+	p := s2.PointFromLatLng(ll)
+	if shapes := s2.NewContainsPointQuery(b.index, s2.VertexModelSemiOpen).ContainingShapes(p); len(shapes) > 0 {
+		return shapes[0].(*area), true
+	}
+	opts := s2.NewClosestEdgeQueryOptions().MaxResults(1).
+		DistanceLimit(s1.ChordAngleFromAngle(earth.AngleFromLength(maxDistance)))
+	results := s2.NewClosestEdgeQuery(b.index, opts).FindEdges(s2.NewMinDistanceToPointTarget(p))
+	if len(results) == 0 {
+		return nil, false
+	}
+	return b.index.Shape(results[0].ShapeID()).(*area), true
+}
@@ -0,0 +1,314 @@
+// A utility that converts GeoJSON polygons to the simplified, compressed boundaries embedded in package geo.
+// See internal/geo/data/README.md for the sources of the embedded boundaries.
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/golang/geo/s2"
+)
+
+var (
+	input      = flag.String("input", "", "GeoJSON file with a FeatureCollection of Polygons and MultiPolygons")
+	output     = flag.String("output", "", "Output file, written as gzipped JSON")
+	properties = flag.String("properties", "", "Comma separated properties to keep, either as name or as name=geojsonName for renaming. Alternatives are separated by |, e.g. country=iso_a2|ISO_A2_EH")
+	tolerance  = flag.Float64("tolerance", 0.01, "Tolerance in degrees for simplifying boundaries")
+)
+
+type feature struct {
+	Properties map[string]any `json:"properties"`
+	Geometry   struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+}
+
+// boundary mirrors the format read by package geo. Loops are counterclockwise lat,lng pairs in 1e-4 degrees, all but
+// the first pair are stored as difference to the previous pair.
+type boundary struct {
+	Properties map[string]string `json:"properties"`
+	Loops      [][]int32         `json:"loops"`
+}
+
+// rings returns the rings of all polygons of the feature, as lng,lat pairs.
+func (f feature) rings() ([][][2]float64, error) {
+	switch f.Geometry.Type {
+	case "Polygon":
+		var p [][][2]float64
+		err := json.Unmarshal(f.Geometry.Coordinates, &p)
+		return p, err
+	case "MultiPolygon":
+		var mp [][][][2]float64
+		if err := json.Unmarshal(f.Geometry.Coordinates, &mp); err != nil {
+			return nil, err
+		}
+		var res [][][2]float64
+		for _, p := range mp {
+			res = append(res, p...)
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("unsupported geometry %q", f.Geometry.Type)
+}
+
+// property returns the given property as string, or an empty string if it's missing. Natural Earth marks missing
+// values as -1 or -99.
+func (f feature) property(name string) string {
+	v, ok := f.Properties[name]
+	if !ok || v == nil {
+		return ""
+	}
+	s := fmt.Sprint(v)
+	if s == "-1" || s == "-99" {
+		return ""
+	}
+	return s
+}
+
+// simplify applies the Douglas-Peucker algorithm to the given points.
+func simplify(points [][2]float64, tolerance float64) [][2]float64 {
+	if len(points) < 3 {
+		return append([][2]float64(nil), points...)
+	}
+	first, last := points[0], points[len(points)-1]
+	maxDist, index := 0.0, 0
+	for i := 1; i < len(points)-1; i++ {
+		if d := segmentDistance(points[i], first, last); d > maxDist {
+			maxDist, index = d, i
+		}
+	}
+	if maxDist <= tolerance {
+		return [][2]float64{first, last}
+	}
+	left := simplify(points[:index+1], tolerance)
+	right := simplify(points[index:], tolerance)
+	return append(left[:len(left)-1], right...)
+}
+
+// segmentDistance returns the planar distance of p to the segment from a to b.
+func segmentDistance(p, a, b [2]float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = math.Max(0, math.Min(1, ((p[0]-a[0])*dx+(p[1]-a[1])*dy)/l))
+	}
+	return math.Hypot(p[0]-a[0]-t*dx, p[1]-a[1]-t*dy)
+}
+
+// encodeRing simplifies and quantizes the given ring. If simplifying results in an invalid loop, e.g. because it
+// intersects itself, the tolerance is reduced. Returns nil if no valid loop with at least three vertices remains.
+func encodeRing(ring [][2]float64, tolerance float64) []int32 {
+	if len(ring) > 1 && ring[0] == ring[len(ring)-1] {
+		ring = ring[:len(ring)-1]
+	}
+	for ; tolerance > 1e-5; tolerance /= 2 {
+		// Split the ring in two halves, so Douglas-Peucker has two distinct endpoints to start from.
+		half := len(ring) / 2
+		second := append(append([][2]float64(nil), ring[half:]...), ring[0])
+		simplified := append(simplify(ring[:half+1], tolerance), simplify(second, tolerance)[1:]...)
+		quantized := quantize(simplified[:len(simplified)-1])
+		if len(quantized) < 3 {
+			continue
+		}
+		quantized = removeIntersections(quantized)
+		if len(quantized) < 3 {
+			continue
+		}
+		orient(quantized)
+		if validLoop(quantized) {
+			return deltaEncode(quantized)
+		}
+	}
+	return nil
+}
+
+// quantize rounds the given lng,lat pairs to lat,lng pairs in 1e-4 degrees, dropping consecutive duplicates.
+func quantize(points [][2]float64) [][2]int32 {
+	res := make([][2]int32, 0, len(points))
+	for _, p := range points {
+		q := [2]int32{int32(math.Round(p[1] * 1e4)), int32(math.Round(p[0] * 1e4))}
+		if len(res) > 0 && res[len(res)-1] == q {
+			continue
+		}
+		res = append(res, q)
+	}
+	if len(res) > 1 && res[0] == res[len(res)-1] {
+		res = res[:len(res)-1]
+	}
+	return res
+}
+
+// orient reverses the given lat,lng pairs if needed, so they are counterclockwise. Simplified rings may intersect
+// themselves, which confuses s2's notion of orientation, so it's determined on the plane.
+func orient(points [][2]int32) {
+	area := 0
+	for i, a := range points {
+		b := points[(i+1)%len(points)]
+		area += int(a[1])*int(b[0]) - int(b[1])*int(a[0])
+	}
+	if area < 0 {
+		slices.Reverse(points)
+	}
+}
+
+// removeIntersections removes vertices where the ring intersects itself. Rounding may cause small intersections even if
+// the source ring is valid, these are removed by dropping one vertex of an intersecting edge at a time.
+func removeIntersections(points [][2]int32) [][2]int32 {
+	for i := 0; i < 10 && len(points) >= 3; i++ {
+		a, ok := selfIntersection(points)
+		if !ok {
+			return points
+		}
+		points = slices.Delete(points, (a+1)%len(points), (a+1)%len(points)+1)
+	}
+	return points
+}
+
+// validLoop returns whether the counterclockwise points form a valid s2 loop with the interior on the left. This fails
+// for rings which intersect themselves and rings that are too small for s2 to determine their orientation.
+func validLoop(points [][2]int32) bool {
+	if _, ok := selfIntersection(points); ok {
+		return false
+	}
+	s2Points := make([]s2.Point, len(points))
+	for i, p := range points {
+		s2Points[i] = s2.PointFromLatLng(s2.LatLngFromDegrees(float64(p[0])/1e4, float64(p[1])/1e4))
+	}
+	loop := s2.LoopFromPoints(s2Points)
+	return loop.Validate() == nil && loop.IsNormalized()
+}
+
+// selfIntersection returns the index of the first vertex of an edge that touches or crosses a non-adjacent edge of the
+// ring on the plane. s2 does not check this when validating loops.
+func selfIntersection(points [][2]int32) (int, bool) {
+	n := len(points)
+	edges := make([]int, n)
+	for i := range edges {
+		edges[i] = i
+	}
+	minLat := func(i int) int32 { return min(points[i][0], points[(i+1)%n][0]) }
+	maxLat := func(i int) int32 { return max(points[i][0], points[(i+1)%n][0]) }
+	// Sweep over edges ordered by their lowest latitude, only comparing edges that overlap in latitude.
+	slices.SortFunc(edges, func(a, b int) int { return int(minLat(a)) - int(minLat(b)) })
+	for i, a := range edges {
+		for _, b := range edges[i+1:] {
+			if minLat(b) > maxLat(a) {
+				break
+			}
+			if (a+1)%n == b || (b+1)%n == a {
+				continue
+			}
+			if segmentsIntersect(points[a], points[(a+1)%n], points[b], points[(b+1)%n]) {
+				return a, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// segmentsIntersect returns whether the segments p1-p2 and p3-p4 share at least one point.
+func segmentsIntersect(p1, p2, p3, p4 [2]int32) bool {
+	d1, d2 := cross(p3, p4, p1), cross(p3, p4, p2)
+	d3, d4 := cross(p1, p2, p3), cross(p1, p2, p4)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	return (d1 == 0 && onSegment(p3, p4, p1)) || (d2 == 0 && onSegment(p3, p4, p2)) ||
+		(d3 == 0 && onSegment(p1, p2, p3)) || (d4 == 0 && onSegment(p1, p2, p4))
+}
+
+// cross returns the orientation of c relative to the line from a to b.
+func cross(a, b, c [2]int32) int64 {
+	return int64(b[0]-a[0])*int64(c[1]-a[1]) - int64(b[1]-a[1])*int64(c[0]-a[0])
+}
+
+// onSegment returns whether c, which is collinear with a and b, lies between them.
+func onSegment(a, b, c [2]int32) bool {
+	return min(a[0], b[0]) <= c[0] && c[0] <= max(a[0], b[0]) && min(a[1], b[1]) <= c[1] && c[1] <= max(a[1], b[1])
+}
+
+func deltaEncode(points [][2]int32) []int32 {
+	res := make([]int32, 0, 2*len(points))
+	for i, p := range points {
+		if i == 0 {
+			res = append(res, p[0], p[1])
+		} else {
+			res = append(res, p[0]-points[i-1][0], p[1]-points[i-1][1])
+		}
+	}
+	return res
+}
+
+func main() {
+	flag.Parse()
+
+	f, err := os.Open(*input)
+	if err != nil {
+		log.Fatalf("Error opening %s: %v", *input, err)
+	}
+	var collection struct {
+		Features []feature `json:"features"`
+	}
+	if err := json.NewDecoder(f).Decode(&collection); err != nil {
+		log.Fatalf("Error decoding %s: %v", *input, err)
+	}
+	f.Close()
+
+	var res struct {
+		Boundaries []boundary `json:"boundaries"`
+	}
+	vertices := 0
+	for _, feat := range collection.Features {
+		b := boundary{Properties: make(map[string]string)}
+		for _, p := range strings.Split(*properties, ",") {
+			name, geojsonNames, found := strings.Cut(p, "=")
+			if !found {
+				geojsonNames = name
+			}
+			for _, geojsonName := range strings.Split(geojsonNames, "|") {
+				if v := feat.property(geojsonName); v != "" {
+					b.Properties[name] = v
+					break
+				}
+			}
+		}
+		rings, err := feat.rings()
+		if err != nil {
+			log.Fatalf("Error reading geometry of %v: %v", b.Properties, err)
+		}
+		for _, r := range rings {
+			if loop := encodeRing(r, *tolerance); loop != nil {
+				b.Loops = append(b.Loops, loop)
+				vertices += len(loop) / 2
+			}
+		}
+		if len(b.Loops) > 0 {
+			res.Boundaries = append(res.Boundaries, b)
+		}
+	}
+
+	out, err := os.Create(*output)
+	if err != nil {
+		log.Fatalf("Error creating %s: %v", *output, err)
+	}
+	defer out.Close()
+	w, err := gzip.NewWriterLevel(out, gzip.BestCompression)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		log.Fatalf("Error writing %s: %v", *output, err)
+	}
+	if err := w.Close(); err != nil {
+		log.Fatalf("Error writing %s: %v", *output, err)
+	}
+	log.Printf("Wrote %d boundaries with %d vertices to %s", len(res.Boundaries), vertices, *output)
+}
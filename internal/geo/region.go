@@ -0,0 +1,41 @@
+package geo
+
+import (
+	_ "embed"
+	"fmt"
+	"sync"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/go-units/unit"
+)
+
+//go:embed data/regions.json.gz
+var regionData []byte
+
+var regions = sync.OnceValues(func() (*boundaries, error) {
+	return decodeBoundaries(regionData)
+})
+
+// Region is a first-level administrative region of a country, e.g. a canton, state or province.
+type Region struct {
+	// Country is the ISO 3166-1 alpha-2 code of the country, e.g. CH. Empty for disputed areas without code.
+	Country string
+	// Code is the ISO 3166-2 code of the region, e.g. CH-ZH.
+	Code string
+	// Name of the region, e.g. Zürich.
+	Name string
+}
+
+// ReverseGeocode returns the region at the given coordinates, looked up from embedded boundaries without any network
+// access. Returns false for locations at sea, more than 20km off the coast. Panics if the embedded data is invalid.
+func ReverseGeocode(ll s2.LatLng) (Region, bool) {
+	b, err := regions()
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded regions: %v", err))
+	}
+	a, ok := b.lookup(ll, 20*unit.Kilometer)
+	if !ok {
+		return Region{}, false
+	}
+	return Region{Country: a.properties["country"], Code: a.properties["region"], Name: a.properties["name"]}, true
+}
@@ -0,0 +1,39 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReverseGeocode(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		ll     s2.LatLng
+		want   Region
+		wantOk bool
+	}{
+		{name: "Zurich", ll: s2.LatLngFromDegrees(47.3769, 8.5417), want: Region{Country: "CH", Code: "CH-ZH", Name: "Zürich"}, wantOk: true},
+		{name: "Basel close to the border", ll: s2.LatLngFromDegrees(47.5596, 7.5886), want: Region{Country: "CH", Code: "CH-BS", Name: "Basel-Stadt"}, wantOk: true},
+		{name: "San Francisco", ll: s2.LatLngFromDegrees(37.7749, -122.4194), want: Region{Country: "US", Code: "US-CA", Name: "California"}, wantOk: true},
+		{name: "Tokyo", ll: s2.LatLngFromDegrees(35.6762, 139.6503), want: Region{Country: "JP", Code: "JP-13", Name: "Tokyo"}, wantOk: true},
+		{name: "Atlantic", ll: s2.LatLngFromDegrees(30, -40)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := ReverseGeocode(tc.ll)
+			if ok != tc.wantOk || !cmp.Equal(got, tc.want) {
+				t.Errorf("ReverseGeocode(%v) = %v, %t, want %v, %t", tc.ll, got, ok, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
+
+func BenchmarkReverseGeocode(b *testing.B) {
+	ll := s2.LatLngFromDegrees(47.3769, 8.5417)
+	ReverseGeocode(ll)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ReverseGeocode(ll)
+	}
+}
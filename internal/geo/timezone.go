@@ -0,0 +1,58 @@
+package geo
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/go-units/unit"
+)
+
+//go:embed data/timezones.json.gz
+var timeZoneData []byte
+
+var (
+	timeZones = sync.OnceValues(func() (*boundaries, error) {
+		return decodeBoundaries(timeZoneData)
+	})
+	// locations caches loaded time zones by name, as loading them is comparably expensive.
+	locations sync.Map
+)
+
+// TimeZone returns the time zone at the given coordinates, looked up from embedded boundaries without any network
+// access. Over the sea, the boundaries contain nautical time zones, e.g. Etc/GMT-9. Panics if the embedded data is
+// invalid.
+func TimeZone(ll s2.LatLng) *time.Location {
+	b, err := timeZones()
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded time zones: %v", err))
+	}
+	name := nauticalTimeZone(ll)
+	if a, ok := b.lookup(ll, 10*unit.Kilometer); ok {
+		name = a.properties["tzid"]
+	}
+	if tz, ok := locations.Load(name); ok {
+		return tz.(*time.Location)
+	}
+	tz, err := time.LoadLocation(name)
+	if err != nil {
+		// Boundaries may be newer than the time zone database.
+		tz, _ = time.LoadLocation(nauticalTimeZone(ll))
+	}
+	locations.Store(name, tz)
+	return tz
+}
+
+// nauticalTimeZone returns the name of the nautical time zone at the given coordinates, which is only based on the
+// longitude. Note that the sign of Etc/GMT zones is inverted, Etc/GMT-1 is one hour ahead of UTC.
+func nauticalTimeZone(ll s2.LatLng) string {
+	offset := int(math.Round(ll.Lng.Degrees() / 15))
+	if offset == 0 {
+		return "Etc/GMT"
+	}
+	return fmt.Sprintf("Etc/GMT%+d", -offset)
+}
@@ -0,0 +1,56 @@
+package geo
+
+import (
+	"testing"
+
+	"github.com/golang/geo/s2"
+)
+
+func TestTimeZone(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		ll   s2.LatLng
+		want string
+	}{
+		{name: "Zurich", ll: s2.LatLngFromDegrees(47.3769, 8.5417), want: "Europe/Zurich"},
+		{name: "Geneva close to the border", ll: s2.LatLngFromDegrees(46.2044, 6.1432), want: "Europe/Zurich"},
+		{name: "San Francisco", ll: s2.LatLngFromDegrees(37.7749, -122.4194), want: "America/Los_Angeles"},
+		{name: "Phoenix", ll: s2.LatLngFromDegrees(33.4484, -112.0740), want: "America/Phoenix"},
+		{name: "Tokyo", ll: s2.LatLngFromDegrees(35.6762, 139.6503), want: "Asia/Tokyo"},
+		{name: "Kolkata", ll: s2.LatLngFromDegrees(22.5726, 88.3639), want: "Asia/Kolkata"},
+		{name: "Sydney", ll: s2.LatLngFromDegrees(-33.8688, 151.2093), want: "Australia/Sydney"},
+		{name: "Pacific", ll: s2.LatLngFromDegrees(0, -150), want: "Etc/GMT+10"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := TimeZone(tc.ll); got.String() != tc.want {
+				t.Errorf("TimeZone(%v) = %s, want %s", tc.ll, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNauticalTimeZone(t *testing.T) {
+	for _, tc := range []struct {
+		lng  float64
+		want string
+	}{
+		{lng: 0, want: "Etc/GMT"},
+		{lng: 7.4, want: "Etc/GMT"},
+		{lng: 7.6, want: "Etc/GMT-1"},
+		{lng: -150, want: "Etc/GMT+10"},
+		{lng: 180, want: "Etc/GMT-12"},
+	} {
+		if got := nauticalTimeZone(s2.LatLngFromDegrees(0, tc.lng)); got != tc.want {
+			t.Errorf("nauticalTimeZone(%v) = %s, want %s", tc.lng, got, tc.want)
+		}
+	}
+}
+
+func BenchmarkTimeZone(b *testing.B) {
+	ll := s2.LatLngFromDegrees(47.3769, 8.5417)
+	TimeZone(ll)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		TimeZone(ll)
+	}
+}
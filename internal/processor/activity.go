@@ -0,0 +1,199 @@
+package processor
+
+import (
+	"cmp"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+// ActivityByTimeBucket is the dominant activity within a time bucket.
+type ActivityByTimeBucket struct {
+	// Activity with the mean confidence of all recognitions of its type within the bucket.
+	Activity reader.Activity
+	Bucket   time.Time
+}
+
+// ActivityDuration is the time spent with an activity type within a time bucket.
+type ActivityDuration struct {
+	Type     string
+	Duration time.Duration
+	Bucket   time.Time
+}
+
+type ActivityOptions struct {
+	// BucketDuration is the duration of buckets, aligned on the wall clock of the time zone. Defaults to a day.
+	BucketDuration time.Duration
+	// MaxGap limits the time attributed to a single recognition. Time between recognitions that are further apart,
+	// e.g. because the phone was turned off, is not attributed to any activity. Defaults to an hour.
+	MaxGap time.Duration
+	// TimeZone used for aligning buckets. Defaults to UTC.
+	TimeZone *time.Location
+	// TimeZoneAt optionally overrides TimeZone with the local time where each location was recorded.
+	TimeZoneAt ZoneFunc
+}
+
+type recognition struct {
+	t        time.Time
+	activity reader.Activity
+	// bucket is the start of the bucket the recognition falls into.
+	bucket time.Time
+}
+
+// activitySum is the sum of confidences of the dominant recognitions of an activity type within a bucket.
+type activitySum struct {
+	confidence int
+	count      int
+}
+
+// ActivityBucketer aggregates the dominant activities recognized with a stream of locations per time bucket, both
+// the time spent per activity type and the dominant activity of every bucket. The time until the next recognition,
+// limited by MaxGap, is attributed to the dominant activity of a recognition, in the bucket the recognition falls into.
+// Locations are expected to be added in ascending order of time.
+type ActivityBucketer struct {
+	opts ActivityOptions
+	// Maps keyed by the UTC time of the bucket.
+	buckets   map[time.Time]time.Time
+	durations map[time.Time]map[string]time.Duration
+	sums      map[time.Time]map[string]activitySum
+	// prev is the latest recognition, if not nil.
+	prev *recognition
+}
+
+// NewActivityBucketer creates an empty ActivityBucketer using the given options.
+func NewActivityBucketer(opts ActivityOptions) *ActivityBucketer {
+	if opts.BucketDuration == 0 {
+		opts.BucketDuration = time.Hour * 24
+	}
+	if opts.MaxGap == 0 {
+		opts.MaxGap = time.Hour
+	}
+	if opts.TimeZone == nil {
+		opts.TimeZone = time.UTC
+	}
+	return &ActivityBucketer{
+		opts:      opts,
+		buckets:   make(map[time.Time]time.Time, 365),
+		durations: make(map[time.Time]map[string]time.Duration, 365),
+		sums:      make(map[time.Time]map[string]activitySum, 365),
+	}
+}
+
+// Add adds the dominant activities of all recognitions of the given location, bucketed in the time zone of the
+// location. It returns an error if the timestamp of a recognition can not be parsed or is older than the previous
+// recognition, in which case the recognition is ignored.
+func (b *ActivityBucketer) Add(loc reader.Location) error {
+	var recognitions []recognition
+	var errs []error
+	tz := zoneAt(b.opts.TimeZone, b.opts.TimeZoneAt, loc.LatLng())
+	for _, a := range loc.Activity {
+		d, ok := a.Dominant()
+		if !ok {
+			continue
+		}
+		t, err := a.ParsedTimestamp()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		recognitions = append(recognitions, recognition{t: t, activity: d, bucket: bucketTimestamp(t, b.opts.BucketDuration, tz)})
+	}
+	slices.SortStableFunc(recognitions, func(a, b recognition) int {
+		return a.t.Compare(b.t)
+	})
+	for _, r := range recognitions {
+		if b.prev != nil {
+			if err := checkOrder(r.t, b.prev.t); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			b.addDuration(*b.prev, min(r.t.Sub(b.prev.t), b.opts.MaxGap))
+		}
+		b.addSum(r)
+		b.prev = &r
+	}
+	return errors.Join(errs...)
+}
+
+// addDuration attributes the given duration to the activity of r.
+func (b *ActivityBucketer) addDuration(r recognition, d time.Duration) {
+	key := r.bucket.UTC()
+	if b.durations[key] == nil {
+		b.durations[key] = make(map[string]time.Duration)
+	}
+	b.durations[key][r.activity.Type] += d
+}
+
+// addSum adds the confidence of r to the sum of its activity type.
+func (b *ActivityBucketer) addSum(r recognition) {
+	key := r.bucket.UTC()
+	b.buckets[key] = r.bucket
+	if b.sums[key] == nil {
+		b.sums[key] = make(map[string]activitySum)
+	}
+	s := b.sums[key][r.activity.Type]
+	b.sums[key][r.activity.Type] = activitySum{confidence: s.confidence + r.activity.Confidence, count: s.count + 1}
+}
+
+// Durations returns the time spent per activity type for every bucket seen so far, ordered by bucket, then by type.
+func (b *ActivityBucketer) Durations() []ActivityDuration {
+	res := make([]ActivityDuration, 0, len(b.durations))
+	for key, byType := range b.durations {
+		for t, d := range byType {
+			res = append(res, ActivityDuration{Type: t, Duration: d, Bucket: b.buckets[key]})
+		}
+	}
+	slices.SortFunc(res, func(a, b ActivityDuration) int {
+		return cmp.Or(a.Bucket.Compare(b.Bucket), cmp.Compare(a.Type, b.Type))
+	})
+	return res
+}
+
+// Dominant returns the dominant activity for every bucket seen so far, i.e. the type with the highest sum of
+// confidences over all dominant activities recognized within the bucket, ordered by bucket.
+func (b *ActivityBucketer) Dominant() []ActivityByTimeBucket {
+	res := make([]ActivityByTimeBucket, 0, len(b.sums))
+	for key, byType := range b.sums {
+		best, found := "", false
+		for t, s := range byType {
+			current := byType[best]
+			if !found || s.confidence > current.confidence || (s.confidence == current.confidence && t < best) {
+				best, found = t, true
+			}
+		}
+		s := byType[best]
+		res = append(res, ActivityByTimeBucket{
+			Activity: reader.Activity{Type: best, Confidence: s.confidence / s.count},
+			Bucket:   b.buckets[key],
+		})
+	}
+	slices.SortFunc(res, func(a, b ActivityByTimeBucket) int {
+		return a.Bucket.Compare(b.Bucket)
+	})
+	return res
+}
+
+// DominantActivityByTimeBucket returns the dominant activity for every bucket in UTC, see ActivityBucketer.Dominant.
+func DominantActivityByTimeBucket(locations []reader.Location, bucketDuration time.Duration) []ActivityByTimeBucket {
+	b := NewActivityBucketer(ActivityOptions{BucketDuration: bucketDuration})
+	for _, loc := range locations {
+		if err := b.Add(loc); err != nil {
+			log.Default().Println(err)
+		}
+	}
+	return b.Dominant()
+}
+
+// TimeByActivity aggregates the time spent per activity type for every bucket, see ActivityBucketer.Durations.
+func TimeByActivity(locations []reader.Location, opts ActivityOptions) []ActivityDuration {
+	b := NewActivityBucketer(opts)
+	for _, loc := range locations {
+		if err := b.Add(loc); err != nil {
+			log.Default().Println(err)
+		}
+	}
+	return b.Durations()
+}
@@ -0,0 +1,103 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+func activities(timestamp string, activity ...reader.Activity) reader.Activities {
+	return reader.Activities{Timestamp: timestamp, Activity: activity}
+}
+
+var activityLocations = []reader.Location{
+	{
+		Timestamp: "2014-04-01T07:55:51.093Z",
+		Activity: []reader.Activities{
+			activities("2014-04-01T08:00:00Z", reader.Activity{Type: "STILL", Confidence: 90}, reader.Activity{Type: "TILTING", Confidence: 10}),
+			activities("2014-04-01T08:10:00Z", reader.Activity{Type: "IN_VEHICLE", Confidence: 80}),
+		},
+	},
+	{
+		Timestamp: "2014-04-01T08:55:51.093Z",
+		Activity: []reader.Activities{
+			activities("2014-04-01T08:20:00Z", reader.Activity{Type: "IN_VEHICLE", Confidence: 60}, reader.Activity{Type: "ON_BICYCLE", Confidence: 30}),
+			activities("2014-04-01T14:00:00Z", reader.Activity{Type: "WALKING", Confidence: 70}),
+			activities("2014-04-01T14:10:00Z"),
+			activities("2014-04-01T14:20:00Z", reader.Activity{Type: "STILL", Confidence: 100}),
+		},
+	},
+}
+
+func TestDominantActivityByTimeBucket(t *testing.T) {
+	want := []ActivityByTimeBucket{
+		{Activity: reader.Activity{Type: "IN_VEHICLE", Confidence: 70}, Bucket: time.Date(2014, 4, 1, 8, 0, 0, 0, time.UTC)},
+		{Activity: reader.Activity{Type: "STILL", Confidence: 100}, Bucket: time.Date(2014, 4, 1, 14, 0, 0, 0, time.UTC)},
+	}
+	got := DominantActivityByTimeBucket(activityLocations, time.Hour)
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("DominantActivityByTimeBucket() = %v, want %v. Diff: %v", got, want, diff)
+	}
+}
+
+func TestTimeByActivity(t *testing.T) {
+	day := parseDate(t, "2014-04-01")
+	want := []ActivityDuration{
+		{Type: "IN_VEHICLE", Duration: 40 * time.Minute, Bucket: day},
+		{Type: "STILL", Duration: 10 * time.Minute, Bucket: day},
+		{Type: "WALKING", Duration: 20 * time.Minute, Bucket: day},
+	}
+	got := TimeByActivity(activityLocations, ActivityOptions{BucketDuration: time.Hour * 24, MaxGap: 30 * time.Minute})
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("TimeByActivity() = %v, want %v. Diff: %v", got, want, diff)
+	}
+}
+
+func TestActivityBucketer(t *testing.T) {
+	zurich, err := time.LoadLocation("Europe/Zurich")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := NewActivityBucketer(ActivityOptions{TimeZone: zurich})
+	for _, tc := range []struct {
+		loc     reader.Location
+		wantErr bool
+	}{
+		{loc: reader.Location{Timestamp: "2014-04-01T21:30:00Z", Activity: []reader.Activities{
+			activities("2014-04-01T21:30:00Z", reader.Activity{Type: "IN_VEHICLE", Confidence: 80}),
+		}}},
+		// Local midnight is at 22:00 UTC, so the time until the next recognition counts for the next day.
+		{loc: reader.Location{Timestamp: "2014-04-01T22:10:00Z", Activity: []reader.Activities{
+			activities("2014-04-01T22:20:00Z", reader.Activity{Type: "STILL", Confidence: 100}),
+			activities("2014-04-01T22:00:00Z", reader.Activity{Type: "WALKING", Confidence: 60}),
+		}}},
+		{loc: reader.Location{Timestamp: "2014-04-01T22:05:00Z", Activity: []reader.Activities{
+			activities("2014-04-01T22:05:00Z", reader.Activity{Type: "IN_VEHICLE", Confidence: 90}),
+		}}, wantErr: true},
+		{loc: reader.Location{Timestamp: "2014-04-01T22:30:00Z", Activity: []reader.Activities{
+			activities("2014-04-01T22:30:00Z", reader.Activity{Type: "STILL", Confidence: 100}),
+		}}},
+	} {
+		if err := b.Add(tc.loc); (err != nil) != tc.wantErr {
+			t.Errorf("Add(%s) = %v, want error: %t", tc.loc.Timestamp, err, tc.wantErr)
+		}
+	}
+	first, second := time.Date(2014, 4, 1, 0, 0, 0, 0, zurich), time.Date(2014, 4, 2, 0, 0, 0, 0, zurich)
+	wantDurations := []ActivityDuration{
+		{Type: "IN_VEHICLE", Duration: 30 * time.Minute, Bucket: first},
+		{Type: "STILL", Duration: 10 * time.Minute, Bucket: second},
+		{Type: "WALKING", Duration: 20 * time.Minute, Bucket: second},
+	}
+	if diff := cmp.Diff(wantDurations, b.Durations()); diff != "" {
+		t.Errorf("Durations() diff (-want +got):\n%s", diff)
+	}
+	wantDominant := []ActivityByTimeBucket{
+		{Activity: reader.Activity{Type: "IN_VEHICLE", Confidence: 80}, Bucket: first},
+		{Activity: reader.Activity{Type: "STILL", Confidence: 100}, Bucket: second},
+	}
+	if diff := cmp.Diff(wantDominant, b.Dominant()); diff != "" {
+		t.Errorf("Dominant() diff (-want +got):\n%s", diff)
+	}
+}
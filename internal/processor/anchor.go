@@ -1,38 +1,78 @@
 package processor
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/golang/geo/earth"
 	"github.com/golang/geo/s2"
+	"github.com/google/go-units/unit"
 )
 
-// Anchor is a location that is used for computing distances, starting at a given date.
+// Anchor is a location that is used for computing distances, starting at a given date. Anchors with different names
+// can be active at the same time, in which case distances are measured to the nearest one.
 type Anchor struct {
 	StartTime time.Time
 	Location  s2.LatLng
+
+	// The following fields are optional and only available for anchors loaded with LoadAnchors.
+
+	// Name identifies the anchor, e.g. "zurich".
+	Name string
+	// Label is a human readable description, e.g. "Home in Zurich".
+	Label string
+	// Radius around the anchor that still counts as being at the anchor.
+	Radius unit.Length
+	// EndTime is the time after which the anchor is not valid anymore. Zero if valid until the next anchor starts.
+	EndTime time.Time
+	// TimeZone of the anchor, nil if unknown.
+	TimeZone *time.Location
+}
+
+// DisplayName returns the label of the anchor, falling back to its name.
+func (a Anchor) DisplayName() string {
+	if a.Label != "" {
+		return a.Label
+	}
+	return a.Name
+}
+
+// zone returns the time zone of the anchor, or the given fallback if it has none.
+func (a Anchor) zone(fallback *time.Location) *time.Location {
+	if a.TimeZone != nil {
+		return a.TimeZone
+	}
+	return fallback
 }
 
 // ParseAnchors parses a string as anchors. The required format is
-// date,lat,lng:date2,lat2,lng2
+// lat,lng or date,lat,lng:date2,lat2,lng2
+// An anchor without date is valid from the beginning of time and can only be given on its own. The anchors have no
+// name, so they form a single sequence where each one is active until the next one starts, e.g. when moving.
 func ParseAnchors(anchors string) ([]Anchor, error) {
 	multidayAnchors := strings.Split(anchors, ":")
-	if len(multidayAnchors) == 1 {
-		var lat, lng float64
-		_, err := fmt.Sscanf(multidayAnchors[0], "%f,%f", &lat, &lng)
-		return []Anchor{{StartTime: time.Time{}, Location: s2.LatLngFromDegrees(lat, lng)}}, err
-	}
 	res := make([]Anchor, 0, len(multidayAnchors))
 	for _, s := range multidayAnchors {
 		split := strings.Split(s, ",")
+		if len(split) == 2 && len(multidayAnchors) == 1 {
+			// Anchor without date, valid for all times.
+			split = append([]string{""}, split...)
+		}
 		if len(split) != 3 {
 			return nil, fmt.Errorf("dated anchor %q does not contain two commas", s)
 		}
-		t, err := time.Parse(time.DateOnly, split[0])
-		if err != nil {
-			return nil, fmt.Errorf("failed parsing date from %q: %w", s, err)
+		var t time.Time
+		if split[0] != "" {
+			var err error
+			t, err = time.Parse(time.DateOnly, split[0])
+			if err != nil {
+				return nil, fmt.Errorf("failed parsing date from %q: %w", s, err)
+			}
 		}
 		lat, err := strconv.ParseFloat(split[1], 64)
 		if err != nil {
@@ -49,3 +89,120 @@ func ParseAnchors(anchors string) ([]Anchor, error) {
 	}
 	return res, nil
 }
+
+// anchorConfig is the representation of an anchor in an anchors file.
+type anchorConfig struct {
+	Name         string  `json:"name"`
+	Label        string  `json:"label"`
+	Lat          float64 `json:"lat"`
+	Lng          float64 `json:"lng"`
+	RadiusMeters float64 `json:"radiusMeters"`
+	// ValidFrom and ValidTo are dates in the format 2006-01-02, ValidTo is inclusive.
+	ValidFrom string `json:"validFrom"`
+	ValidTo   string `json:"validTo"`
+	// TimeZone is an IANA time zone name, e.g. Europe/Zurich.
+	TimeZone string `json:"timeZone"`
+}
+
+// LoadAnchors reads anchors from a JSON anchors file of the form
+//
+//	{"anchors": [{"name": "zurich", "label": "Home in Zurich", "lat": 47.37, "lng": 8.54, "radiusMeters": 500,
+//	  "validFrom": "2014-01-01", "validTo": "2016-01-31", "timeZone": "Europe/Zurich"}]}
+//
+// The name is required, as anchors with the same name form a sequence, see activeAnchors. All other fields but lat and
+// lng are optional. Dates are interpreted in the anchor's time zone, or UTC if there is none. The result is ordered by
+// StartTime.
+func LoadAnchors(r io.Reader) ([]Anchor, error) {
+	var file struct {
+		Anchors []anchorConfig `json:"anchors"`
+	}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("decoding anchors: %w", err)
+	}
+	res := make([]Anchor, 0, len(file.Anchors))
+	for i, c := range file.Anchors {
+		if c.Name == "" {
+			return nil, fmt.Errorf("anchor %d has no name", i)
+		}
+		a := Anchor{
+			Name:     c.Name,
+			Label:    c.Label,
+			Location: s2.LatLngFromDegrees(c.Lat, c.Lng),
+			Radius:   unit.Length(c.RadiusMeters) * unit.Meter,
+		}
+		tz := time.UTC
+		if c.TimeZone != "" {
+			var err error
+			a.TimeZone, err = time.LoadLocation(c.TimeZone)
+			if err != nil {
+				return nil, fmt.Errorf("failed loading time zone of anchor %q: %w", c.Name, err)
+			}
+			tz = a.TimeZone
+		}
+		if c.ValidFrom != "" {
+			t, err := time.ParseInLocation(time.DateOnly, c.ValidFrom, tz)
+			if err != nil {
+				return nil, fmt.Errorf("failed parsing validFrom of anchor %q: %w", c.Name, err)
+			}
+			a.StartTime = t
+		}
+		if c.ValidTo != "" {
+			t, err := time.ParseInLocation(time.DateOnly, c.ValidTo, tz)
+			if err != nil {
+				return nil, fmt.Errorf("failed parsing validTo of anchor %q: %w", c.Name, err)
+			}
+			a.EndTime = t.AddDate(0, 0, 1)
+			if !a.EndTime.After(a.StartTime) {
+				return nil, fmt.Errorf("validTo %s of anchor %q is before validFrom %s", c.ValidTo, c.Name, c.ValidFrom)
+			}
+		}
+		res = append(res, a)
+	}
+	slices.SortStableFunc(res, func(a, b Anchor) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
+	return res, nil
+}
+
+// activeAnchors returns all anchors that are active at the given time. Anchors with the same name form a sequence,
+// where each anchor is active until the next one with that name starts, or until its EndTime. Anchors with different
+// names, e.g. "home" and "office", can be active at the same time. The earliest anchors are also used for all times
+// before their StartTime. Assumes anchors are ordered by StartTime.
+func activeAnchors(anchors []Anchor, t time.Time) []Anchor {
+	var res []Anchor
+	for i, a := range anchors {
+		started := t.After(a.StartTime) || a.StartTime.Equal(anchors[0].StartTime)
+		ended := !a.EndTime.IsZero() && !t.Before(a.EndTime)
+		if !started || ended {
+			continue
+		}
+		superseded := slices.ContainsFunc(anchors[i+1:], func(b Anchor) bool {
+			return b.Name == a.Name && t.After(b.StartTime)
+		})
+		if !superseded {
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+// nearestAnchor returns the anchor closest to the given point out of all anchors active at time t, together with
+// its distance. Points within the Radius of an anchor are at the anchor, i.e. their distance is zero. Returns false if
+// there is no active anchor.
+func nearestAnchor(anchors []Anchor, latlng s2.LatLng, t time.Time) (Anchor, unit.Length, bool) {
+	var nearest Anchor
+	var dist unit.Length
+	found := false
+	for _, a := range activeAnchors(anchors, t) {
+		d := earth.LengthFromAngle(latlng.Distance(a.Location))
+		if d <= a.Radius {
+			d = 0
+		}
+		if !found || d < dist {
+			nearest, dist, found = a, d, true
+		}
+	}
+	return nearest, dist, found
+}
@@ -1,12 +1,14 @@
 package processor
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/geo/s2"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-units/unit"
 )
 
 func TestParseAnchors(t *testing.T) {
@@ -24,6 +26,15 @@ func TestParseAnchors(t *testing.T) {
 					Location:  s2.LatLngFromDegrees(10, 15),
 				},
 			},
+		}, {
+			name:  "One anchor with date",
+			input: "2007-01-31,10.0,15.0",
+			want: []Anchor{
+				{
+					StartTime: time.Date(2007, 1, 31, 0, 0, 0, 0, time.UTC),
+					Location:  s2.LatLngFromDegrees(10, 15),
+				},
+			},
 		}, {
 			name:  "Two anchors",
 			input: "2007-01-31,10.0,15.0:2007-02-12,11.0,16.0",
@@ -47,3 +58,179 @@ func TestParseAnchors(t *testing.T) {
 	}
 
 }
+
+func TestParseAnchorsInvalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"10,15,20,25",
+		"10,15:2007-02-12,11.0,16.0",
+		"2007-01-31,north,15.0",
+	} {
+		if got, err := ParseAnchors(input); err == nil {
+			t.Errorf("ParseAnchors(%q) = %v, want error", input, got)
+		}
+	}
+}
+
+func TestLoadAnchors(t *testing.T) {
+	input := `{
+		"anchors": [{
+			"name": "sf",
+			"label": "Home in San Francisco",
+			"lat": 37.77,
+			"lng": -122.42,
+			"validFrom": "2016-02-01",
+			"timeZone": "America/Los_Angeles"
+		}, {
+			"name": "zurich",
+			"lat": 47.37,
+			"lng": 8.54,
+			"radiusMeters": 500,
+			"validFrom": "2014-01-01",
+			"validTo": "2016-01-30"
+		}]
+	}`
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Anchor{
+		{
+			Name:      "zurich",
+			StartTime: time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2016, 1, 31, 0, 0, 0, 0, time.UTC),
+			Location:  s2.LatLngFromDegrees(47.37, 8.54),
+			Radius:    500 * unit.Meter,
+		}, {
+			Name:      "sf",
+			Label:     "Home in San Francisco",
+			StartTime: time.Date(2016, 2, 1, 0, 0, 0, 0, la),
+			Location:  s2.LatLngFromDegrees(37.77, -122.42),
+			TimeZone:  la,
+		},
+	}
+	sameZone := cmp.Comparer(func(a, b *time.Location) bool {
+		return a.String() == b.String()
+	})
+	got, err := LoadAnchors(strings.NewReader(input))
+	if err != nil || !cmp.Equal(got, want, approxLatLng, sameZone) {
+		t.Errorf("LoadAnchors() = %v, %v, want %v", got, err, want)
+	}
+	if got[0].DisplayName() != "zurich" || got[1].DisplayName() != "Home in San Francisco" {
+		t.Errorf("DisplayName() = %q, %q, want zurich and label", got[0].DisplayName(), got[1].DisplayName())
+	}
+	// Between the two anchors, no anchor is active.
+	if a := activeAnchors(got, time.Date(2016, 1, 31, 12, 0, 0, 0, time.UTC)); len(a) != 0 {
+		t.Errorf("activeAnchors() = %v, want none", a)
+	}
+}
+
+func TestLoadAnchorsInvalid(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input string
+	}{
+		{name: "Without name", input: `{"anchors": [{"lat": 47.37, "lng": 8.54}]}`},
+		{name: "Unknown field", input: `{"anchors": [{"name": "zurich", "lat": 47.37, "lng": 8.54, "radius": 500}]}`},
+		{name: "Unknown time zone", input: `{"anchors": [{"name": "zurich", "lat": 47.37, "lng": 8.54, "timeZone": "Mars/Olympus"}]}`},
+		{name: "Ends before start", input: `{"anchors": [{"name": "zurich", "lat": 47.37, "lng": 8.54, "validFrom": "2016-01-30", "validTo": "2016-01-29"}]}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got, err := LoadAnchors(strings.NewReader(tc.input)); err == nil {
+				t.Errorf("LoadAnchors() = %v, want error", got)
+			}
+		})
+	}
+}
+
+func TestActiveAnchors(t *testing.T) {
+	anchors := []Anchor{
+		{StartTime: time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC), Location: s2.LatLngFromDegrees(1, 1)},
+		{StartTime: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC), Location: s2.LatLngFromDegrees(2, 2)},
+		{StartTime: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), Location: s2.LatLngFromDegrees(3, 3)},
+	}
+	for _, tc := range []struct {
+		name string
+		t    time.Time
+		want []Anchor
+	}{
+		{name: "Before first anchor", t: time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC), want: anchors[:1]},
+		{name: "During first anchor", t: time.Date(2014, 6, 1, 0, 0, 0, 0, time.UTC), want: anchors[:1]},
+		{name: "At start of second anchor", t: anchors[1].StartTime, want: anchors[:1]},
+		{name: "During second anchor", t: time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC), want: anchors[1:2]},
+		{name: "After last anchor", t: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), want: anchors[2:]},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := activeAnchors(anchors, tc.t); !cmp.Equal(got, tc.want) {
+				t.Errorf("activeAnchors(%v) = %v, want %v", tc.t, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestActiveAnchorsSimultaneous(t *testing.T) {
+	anchors := []Anchor{
+		{Name: "home", StartTime: time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC), Location: s2.LatLngFromDegrees(1, 1)},
+		{Name: "office", StartTime: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC), Location: s2.LatLngFromDegrees(2, 2),
+			EndTime: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Name: "home", StartTime: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), Location: s2.LatLngFromDegrees(3, 3)},
+	}
+	for _, tc := range []struct {
+		name string
+		t    time.Time
+		want []Anchor
+	}{
+		{name: "Before office", t: time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC), want: anchors[:1]},
+		{name: "First home and office", t: time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC), want: anchors[:2]},
+		{name: "Second home and office", t: time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC), want: anchors[1:]},
+		{name: "After office ended", t: time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), want: anchors[2:]},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := activeAnchors(anchors, tc.t); !cmp.Equal(got, tc.want) {
+				t.Errorf("activeAnchors(%v) = %v, want %v", tc.t, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNearestAnchor(t *testing.T) {
+	anchors := []Anchor{
+		{Name: "home", Location: s2.LatLngFromDegrees(47, 8)},
+		{Name: "office", Location: s2.LatLngFromDegrees(47, 9)},
+	}
+	ts := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	got, dist, ok := nearestAnchor(anchors, s2.LatLngFromDegrees(47.1, 8.9), ts)
+	if !ok || got.Name != "office" {
+		t.Errorf("nearestAnchor() = %v, %v, %t, want office", got, dist, ok)
+	}
+	if want := 13.46 * unit.Kilometer; !cmp.Equal(dist, want, toKilometers, cmpopts.EquateApprox(0, 0.1)) {
+		t.Errorf("nearestAnchor() distance = %v, want %v", dist, want)
+	}
+	if got, _, ok := nearestAnchor(nil, s2.LatLngFromDegrees(47, 8), ts); ok {
+		t.Errorf("nearestAnchor(nil) = %v, want none", got)
+	}
+}
+
+func TestNearestAnchorRadius(t *testing.T) {
+	anchors := []Anchor{
+		{Name: "home", Location: s2.LatLngFromDegrees(47, 8), Radius: 2 * unit.Kilometer},
+		{Name: "office", Location: s2.LatLngFromDegrees(47.02, 8)},
+	}
+	ts := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name     string
+		latlng   s2.LatLng
+		want     string
+		wantDist unit.Length
+	}{
+		{name: "Within radius of home, although closer to the office", latlng: s2.LatLngFromDegrees(47.015, 8), want: "home"},
+		{name: "Outside radius", latlng: s2.LatLngFromDegrees(46.97, 8), want: "home", wantDist: 3.336 * unit.Kilometer},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, dist, ok := nearestAnchor(anchors, tc.latlng, ts)
+			if !ok || got.Name != tc.want || !cmp.Equal(dist, tc.wantDist, toKilometers, cmpopts.EquateApprox(0, 0.001)) {
+				t.Errorf("nearestAnchor(%v) = %v, %v, %t, want %s, %v", tc.latlng, got.Name, dist, ok, tc.want, tc.wantDist)
+			}
+		})
+	}
+}
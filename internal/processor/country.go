@@ -0,0 +1,134 @@
+package processor
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/panmari/locationhistory/internal/geo"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+// CountryDay lists the countries and regions visited on a single day.
+type CountryDay struct {
+	Day time.Time
+	// Countries as ISO 3166-1 alpha-2 codes, in order of the first location in each country.
+	Countries []string
+	// Regions as ISO 3166-2 codes, in order of the first location in each region.
+	Regions []string
+}
+
+type CountryOptions struct {
+	// Geocode returns the region at the given coordinates. Defaults to geo.ReverseGeocode.
+	Geocode func(s2.LatLng) (geo.Region, bool)
+	// TimeZone used for determining days. Defaults to UTC.
+	TimeZone *time.Location
+	// TimeZoneAt optionally overrides TimeZone with the local time where each location was recorded.
+	TimeZoneAt ZoneFunc
+}
+
+// CountryTracker records the countries and regions visited on each day from a stream of locations. Days are the daily
+// buckets of TimeBucketDistance, i.e. they are split at midnight in the time zone of each location.
+type CountryTracker struct {
+	opts CountryOptions
+	days map[time.Time]*CountryDay
+}
+
+// NewCountryTracker creates an empty CountryTracker using the given options.
+func NewCountryTracker(opts CountryOptions) *CountryTracker {
+	if opts.Geocode == nil {
+		opts.Geocode = geo.ReverseGeocode
+	}
+	if opts.TimeZone == nil {
+		opts.TimeZone = time.UTC
+	}
+	return &CountryTracker{
+		opts: opts,
+		days: make(map[time.Time]*CountryDay, 365),
+	}
+}
+
+// Add records the country of the given location. It returns an error if the location's timestamp can not be parsed,
+// in which case the location is ignored. Locations at sea or in areas without country code are ignored as well.
+func (c *CountryTracker) Add(loc reader.Location) error {
+	t, err := loc.ParsedTimestamp()
+	if err != nil {
+		return err
+	}
+	latlng := loc.LatLng()
+	region, ok := c.opts.Geocode(latlng)
+	if !ok || region.Country == "" {
+		return nil
+	}
+	tz := zoneAt(c.opts.TimeZone, c.opts.TimeZoneAt, latlng)
+	// Keyed by the date rather than the bucket, so that days in different time zones are merged.
+	day := date(bucketTimestamp(t, time.Hour*24, tz))
+	d := c.days[day]
+	if d == nil {
+		d = &CountryDay{Day: day}
+		c.days[day] = d
+	}
+	if !slices.Contains(d.Countries, region.Country) {
+		d.Countries = append(d.Countries, region.Country)
+	}
+	if region.Code != "" && !slices.Contains(d.Regions, region.Code) {
+		d.Regions = append(d.Regions, region.Code)
+	}
+	return nil
+}
+
+// Days returns all days with at least one location in a country, ordered by time.
+func (c *CountryTracker) Days() []CountryDay {
+	res := make([]CountryDay, 0, len(c.days))
+	for _, d := range c.days {
+		res = append(res, *d)
+	}
+	slices.SortFunc(res, func(a, b CountryDay) int {
+		return a.Day.Compare(b.Day)
+	})
+	return res
+}
+
+// CountryVisit is the number of days with at least one location in a country.
+type CountryVisit struct {
+	Country string
+	Days    int
+}
+
+// CountryYear lists the countries visited in a single year.
+type CountryYear struct {
+	Year int
+	// Countries ordered by the number of days spent, descending.
+	Countries []CountryVisit
+}
+
+// CountriesByYear counts the days spent in each country per year. Days spent in several countries count for each of
+// them.
+func CountriesByYear(days []CountryDay) []CountryYear {
+	var res []CountryYear
+	counts := make(map[string]int)
+	flush := func(year int) {
+		y := CountryYear{Year: year}
+		for country, n := range counts {
+			y.Countries = append(y.Countries, CountryVisit{Country: country, Days: n})
+		}
+		slices.SortFunc(y.Countries, func(a, b CountryVisit) int {
+			return cmp.Or(b.Days-a.Days, cmp.Compare(a.Country, b.Country))
+		})
+		res = append(res, y)
+		clear(counts)
+	}
+	for i, d := range days {
+		if i > 0 && d.Day.Year() != days[i-1].Day.Year() {
+			flush(days[i-1].Day.Year())
+		}
+		for _, country := range d.Countries {
+			counts[country]++
+		}
+	}
+	if len(days) > 0 {
+		flush(days[len(days)-1].Day.Year())
+	}
+	return res
+}
@@ -0,0 +1,74 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/panmari/locationhistory/internal/geo"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+func TestCountryTracker(t *testing.T) {
+	start := time.Date(2019, 12, 31, 12, 0, 0, 0, time.UTC)
+	locations := []reader.Location{
+		// Zurich, then a trip to Lörrach across the border from Basel.
+		location(start, 47.3769, 8.5417),
+		location(start.Add(time.Hour), 47.5596, 7.5886),
+		location(start.Add(2*time.Hour), 47.6156, 7.6614),
+		// Atlantic, ignored.
+		location(start.AddDate(0, 0, 1), 30, -40),
+		// Tokyo at 23:00 UTC, which is already the next day in Japan.
+		location(start.AddDate(0, 0, 1).Add(11*time.Hour), 35.6762, 139.6503),
+	}
+	for _, tc := range []struct {
+		name string
+		opts CountryOptions
+		want []CountryDay
+	}{
+		{
+			name: "UTC",
+			want: []CountryDay{
+				{Day: parseDate(t, "2019-12-31"), Countries: []string{"CH", "DE"}, Regions: []string{"CH-ZH", "CH-BS", "DE-BW"}},
+				{Day: parseDate(t, "2020-01-01"), Countries: []string{"JP"}, Regions: []string{"JP-13"}},
+			},
+		},
+		{
+			name: "Local time",
+			opts: CountryOptions{TimeZoneAt: geo.TimeZone},
+			want: []CountryDay{
+				{Day: parseDate(t, "2019-12-31"), Countries: []string{"CH", "DE"}, Regions: []string{"CH-ZH", "CH-BS", "DE-BW"}},
+				{Day: parseDate(t, "2020-01-02"), Countries: []string{"JP"}, Regions: []string{"JP-13"}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewCountryTracker(tc.opts)
+			for _, loc := range locations {
+				if err := c.Add(loc); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if diff := cmp.Diff(tc.want, c.Days()); diff != "" {
+				t.Errorf("Days() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCountriesByYear(t *testing.T) {
+	days := []CountryDay{
+		{Day: parseDate(t, "2019-12-30"), Countries: []string{"CH"}},
+		{Day: parseDate(t, "2019-12-31"), Countries: []string{"CH", "DE"}},
+		{Day: parseDate(t, "2020-01-01"), Countries: []string{"JP"}},
+		{Day: parseDate(t, "2020-01-02"), Countries: []string{"JP"}},
+		{Day: parseDate(t, "2020-01-03"), Countries: []string{"CH"}},
+	}
+	want := []CountryYear{
+		{Year: 2019, Countries: []CountryVisit{{Country: "CH", Days: 2}, {Country: "DE", Days: 1}}},
+		{Year: 2020, Countries: []CountryVisit{{Country: "JP", Days: 2}, {Country: "CH", Days: 1}}},
+	}
+	if diff := cmp.Diff(want, CountriesByYear(days)); diff != "" {
+		t.Errorf("CountriesByYear() diff (-want +got):\n%s", diff)
+	}
+}
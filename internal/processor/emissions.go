@@ -0,0 +1,76 @@
+package processor
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EmissionFactors are the grams of CO2 equivalent emitted per passenger kilometer with each mode of transport.
+type EmissionFactors map[Mode]float64
+
+// DefaultEmissionFactors are rough averages in line with the UK government's greenhouse gas conversion factors:
+// an average petrol car with a single occupant, national rail, and economy flights including the effect of
+// non-CO2 emissions at altitude.
+var DefaultEmissionFactors = EmissionFactors{
+	Walking: 0,
+	Cycling: 0,
+	Driving: 170,
+	Train:   35,
+	Flight:  150,
+}
+
+// ParseEmissionFactors parses factors in the format mode=grams,mode2=grams, e.g. driving=120,flight=200. Modes that
+// are not given keep their default factor.
+func ParseEmissionFactors(factors string) (EmissionFactors, error) {
+	res := maps.Clone(DefaultEmissionFactors)
+	if factors == "" {
+		return res, nil
+	}
+	for _, f := range strings.Split(factors, ",") {
+		mode, grams, ok := strings.Cut(f, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid emission factor %q, expected mode=grams", f)
+		}
+		if _, ok := res[Mode(mode)]; !ok {
+			return nil, fmt.Errorf("unknown mode of transport %q in %q", mode, f)
+		}
+		g, err := strconv.ParseFloat(grams, 64)
+		if err != nil || g < 0 {
+			return nil, fmt.Errorf("invalid grams in emission factor %q", f)
+		}
+		res[Mode(mode)] = g
+	}
+	return res, nil
+}
+
+// Kilograms returns the kilograms of CO2 equivalent emitted for the distance traveled in the given bucket.
+func (f EmissionFactors) Kilograms(m ModeByTimeBucket) float64 {
+	return f[m.Mode] * m.Distance.Kilometers() / 1000
+}
+
+// TotalKilograms returns the kilograms of CO2 equivalent emitted for the distance traveled in all given buckets.
+func (f EmissionFactors) TotalKilograms(items []ModeByTimeBucket) float64 {
+	total := 0.0
+	for _, m := range items {
+		total += f.Kilograms(m)
+	}
+	return total
+}
+
+// TripKilograms returns the kilograms of CO2 equivalent emitted during the given trip, based on the daily buckets of
+// a ModeBucketer. The journeys to and from the destination are included, as they happen on the first and the last
+// day of the trip.
+func (f EmissionFactors) TripKilograms(trip Trip, days []ModeByTimeBucket) float64 {
+	start, end := trip.Start.Format(time.DateOnly), trip.End.Format(time.DateOnly)
+	total := 0.0
+	for _, m := range days {
+		// Compare dates, as days and trips may be in different time zones.
+		if day := m.Bucket.Format(time.DateOnly); day >= start && day <= end {
+			total += f.Kilograms(m)
+		}
+	}
+	return total
+}
@@ -0,0 +1,88 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-units/unit"
+)
+
+func TestParseEmissionFactors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		factors string
+		want    EmissionFactors
+		wantErr bool
+	}{
+		{name: "Defaults", factors: "", want: DefaultEmissionFactors},
+		{
+			name:    "Overrides",
+			factors: "driving=120,flight=200.5",
+			want:    EmissionFactors{Walking: 0, Cycling: 0, Driving: 120, Train: 35, Flight: 200.5},
+		},
+		{name: "Missing grams", factors: "driving", wantErr: true},
+		{name: "Unknown mode", factors: "boat=20", wantErr: true},
+		{name: "Negative grams", factors: "train=-1", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseEmissionFactors(tc.factors)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ParseEmissionFactors(%q) error = %v, wantErr %v", tc.factors, err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ParseEmissionFactors(%q) diff (-want +got):\n%s", tc.factors, diff)
+			}
+		})
+	}
+	if DefaultEmissionFactors[Driving] != 170 {
+		t.Errorf("ParseEmissionFactors modified the defaults: %v", DefaultEmissionFactors)
+	}
+}
+
+func TestEmissionFactors(t *testing.T) {
+	factors := EmissionFactors{Walking: 0, Driving: 100, Train: 30, Flight: 200}
+	days := []ModeByTimeBucket{
+		{Mode: Driving, Distance: 10 * unit.Kilometer, Bucket: parseDate(t, "2024-04-01")},
+		{Mode: Flight, Distance: 1000 * unit.Kilometer, Bucket: parseDate(t, "2024-04-02")},
+		{Mode: Walking, Distance: 5 * unit.Kilometer, Bucket: parseDate(t, "2024-04-03")},
+		{Mode: Train, Distance: 200 * unit.Kilometer, Bucket: parseDate(t, "2024-04-03")},
+		{Mode: Flight, Distance: 1000 * unit.Kilometer, Bucket: parseDate(t, "2024-04-05")},
+		{Mode: Driving, Distance: 20 * unit.Kilometer, Bucket: parseDate(t, "2024-04-06")},
+	}
+	zurich, err := time.LoadLocation("Europe/Zurich")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{name: "Single bucket", got: factors.Kilograms(days[1]), want: 200},
+		{name: "Total", got: factors.TotalKilograms(days), want: 1 + 200 + 6 + 200 + 2},
+		{
+			name: "Trip including first and last day",
+			got: factors.TripKilograms(Trip{
+				Start: parseDate(t, "2024-04-02"),
+				End:   parseDate(t, "2024-04-05"),
+			}, days),
+			want: 200 + 6 + 200,
+		},
+		{
+			name: "Trip in other time zone",
+			got: factors.TripKilograms(Trip{
+				Start: time.Date(2024, 4, 5, 0, 0, 0, 0, zurich),
+				End:   time.Date(2024, 4, 6, 0, 0, 0, 0, zurich),
+			}, days),
+			want: 200 + 2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.got, cmpopts.EquateApprox(0, 0.001)); diff != "" {
+				t.Errorf("diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
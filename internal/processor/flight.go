@@ -0,0 +1,163 @@
+package processor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/geo/earth"
+	"github.com/golang/geo/s2"
+	"github.com/google/go-units/unit"
+	"github.com/panmari/locationhistory/internal/geo"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+// FlightLeg is a single flight from takeoff to landing, detected from the locations before, during and after it.
+type FlightLeg struct {
+	// Departure is the time of the last location before the flight, Arrival of the first location after it. Both are
+	// in the time zone of the respective location.
+	Departure time.Time
+	Arrival   time.Time
+	// From and To are the airports nearest to the first and last location of the flight. The IATA code is empty if
+	// there is no known airport nearby, in which case FromLatLng or ToLatLng is the location itself.
+	From       geo.Airport
+	To         geo.Airport
+	FromLatLng s2.LatLng
+	ToLatLng   s2.LatLng
+	// Distance is the great-circle distance between the endpoints.
+	Distance unit.Length
+}
+
+// Route returns the route of the flight as IATA codes, e.g. ZRH-LHR. Endpoints without known airport are shown as
+// coordinates.
+func (f FlightLeg) Route() string {
+	name := func(a geo.Airport, ll s2.LatLng) string {
+		if a.IATA != "" {
+			return a.IATA
+		}
+		return fmt.Sprintf("(%.2f,%.2f)", ll.Lat.Degrees(), ll.Lng.Degrees())
+	}
+	return name(f.From, f.FromLatLng) + "-" + name(f.To, f.ToLatLng)
+}
+
+type FlightOptions struct {
+	// MinDistance is the minimum great-circle distance of a flight, so outliers of inaccurate locations are skipped.
+	// Defaults to 100km.
+	MinDistance unit.Length
+	// MaxAirportDistance is the maximum distance of an endpoint from an airport for snapping it. The endpoints of
+	// flights are often recorded on the way to or from the airport, e.g. with the phone turned off at the airport.
+	// Defaults to 100km.
+	MaxAirportDistance unit.Length
+	// NearestAirport returns the airport nearest to the given coordinates within the given distance. Defaults to
+	// geo.NearestAirport.
+	NearestAirport func(s2.LatLng, unit.Length) (geo.Airport, bool)
+	// TimeZone used for departure and arrival times. Defaults to UTC.
+	TimeZone *time.Location
+	// TimeZoneAt optionally overrides TimeZone with the local time of the respective location.
+	TimeZoneAt ZoneFunc
+}
+
+// FlightDetector detects flights from a stream of locations. Flights show up as a few fixes hundreds of kilometers
+// apart within hours, as phones are usually in flight mode. Consecutive segments that are either too fast for a train
+// or long jumps of at least 300km within a day at 80km/h or more form a flight.
+// Locations are expected to be added in ascending order of time.
+type FlightDetector struct {
+	opts    FlightOptions
+	flights []FlightLeg
+	prev    *fix
+	// from and to are the first and last fix of the flight in progress, if from is not nil.
+	from, to *fix
+}
+
+// NewFlightDetector creates an empty FlightDetector using the given options.
+func NewFlightDetector(opts FlightOptions) *FlightDetector {
+	if opts.MinDistance == 0 {
+		opts.MinDistance = 100 * unit.Kilometer
+	}
+	if opts.MaxAirportDistance == 0 {
+		opts.MaxAirportDistance = 100 * unit.Kilometer
+	}
+	if opts.NearestAirport == nil {
+		opts.NearestAirport = geo.NearestAirport
+	}
+	if opts.TimeZone == nil {
+		opts.TimeZone = time.UTC
+	}
+	return &FlightDetector{opts: opts}
+}
+
+// Add adds the segment from the previous location to the given one. A segment is part of a flight if it is a flight
+// by its length and time, or if FLYING is the dominant activity recognized at its end. It returns an error if the
+// location's timestamp can not be parsed or is older than the previous one, in which case the location is ignored.
+func (d *FlightDetector) Add(loc reader.Location) error {
+	t, err := loc.ParsedTimestamp()
+	if err != nil {
+		return err
+	}
+	f := newFix(t, loc)
+	if d.prev == nil {
+		d.prev = &f
+		return nil
+	}
+	if err := checkOrder(t, d.prev.t); err != nil {
+		return err
+	}
+	if t.Equal(d.prev.t) {
+		// Without time, there is no speed for detecting a flight.
+		return nil
+	}
+	prev := d.prev
+	d.prev = &f
+	length := earth.LengthFromAngle(prev.latlng.Distance(f.latlng))
+	activity, ok := loc.DominantActivity()
+	flying := ok && activityModes[activity.Type] == Flight
+	if !flying && !isFlight(length, t.Sub(prev.t)) {
+		d.finish()
+		return nil
+	}
+	if d.from == nil {
+		d.from = prev
+	}
+	d.to = &f
+	return nil
+}
+
+// finish adds the flight in progress, if any, unless it is shorter than MinDistance.
+func (d *FlightDetector) finish() {
+	if d.from == nil {
+		return
+	}
+	if flight, ok := d.flight(*d.from, *d.to); ok {
+		d.flights = append(d.flights, flight)
+	}
+	d.from, d.to = nil, nil
+}
+
+// flight returns the flight between the given fixes, snapping them to the nearest airports. Returns false if the
+// flight is shorter than MinDistance.
+func (d *FlightDetector) flight(from, to fix) (FlightLeg, bool) {
+	res := FlightLeg{FromLatLng: from.latlng, ToLatLng: to.latlng}
+	if a, ok := d.opts.NearestAirport(from.latlng, d.opts.MaxAirportDistance); ok {
+		res.From, res.FromLatLng = a, a.LatLng
+	}
+	if a, ok := d.opts.NearestAirport(to.latlng, d.opts.MaxAirportDistance); ok {
+		res.To, res.ToLatLng = a, a.LatLng
+	}
+	res.Distance = earth.LengthFromAngle(res.FromLatLng.Distance(res.ToLatLng))
+	if res.Distance < d.opts.MinDistance {
+		return FlightLeg{}, false
+	}
+	res.Departure = from.t.In(zoneAt(d.opts.TimeZone, d.opts.TimeZoneAt, from.latlng))
+	res.Arrival = to.t.In(zoneAt(d.opts.TimeZone, d.opts.TimeZoneAt, to.latlng))
+	return res, true
+}
+
+// Flights returns the flights detected so far, ordered by departure, including a flight in progress.
+func (d *FlightDetector) Flights() []FlightLeg {
+	res := d.flights
+	if d.from != nil {
+		if flight, ok := d.flight(*d.from, *d.to); ok {
+			res = append(res[:len(res):len(res)], flight)
+		}
+	}
+	return res
+}
@@ -0,0 +1,192 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-units/unit"
+	"github.com/panmari/locationhistory/internal/geo"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+func TestFlightDetector(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 4, day, hour, minute, 0, 0, time.UTC)
+	}
+	zurich, err := time.LoadLocation("Europe/Zurich")
+	if err != nil {
+		t.Fatal(err)
+	}
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zrh, _ := geo.NearestAirport(s2.LatLngFromDegrees(47.46, 8.55), unit.Kilometer)
+	lhr, _ := geo.NearestAirport(s2.LatLngFromDegrees(51.47, -0.45), unit.Kilometer)
+	locations := []reader.Location{
+		// Home in Zurich, then driving to the airport.
+		location(at(1, 6, 0), 47.37, 8.54),
+		location(at(1, 6, 30), 47.45, 8.56),
+		// Phone turned off during the flight, turned on after landing at Heathrow.
+		location(at(1, 10, 0), 51.47, -0.45),
+		location(at(1, 11, 0), 51.51, -0.13),
+		// An outlier implying a fast, but short flight.
+		location(at(2, 9, 0), 51.51, -0.13),
+		location(at(2, 9, 1), 51.9, -0.13),
+		location(at(2, 9, 2), 51.51, -0.13),
+		// Flight back with a few fixes during the flight, landing with the phone still on.
+		location(at(5, 18, 0), 51.47, -0.45),
+		location(at(5, 18, 30), 50.5, 2.5),
+		location(at(5, 19, 0), 48.8, 5.9),
+		location(at(5, 19, 30), 47.46, 8.55),
+		location(at(5, 20, 0), 47.40, 8.55),
+	}
+	d := NewFlightDetector(FlightOptions{TimeZoneAt: func(ll s2.LatLng) *time.Location {
+		if ll.Lng.Degrees() < 3 {
+			return london
+		}
+		return zurich
+	}})
+	for _, loc := range locations {
+		if err := d.Add(loc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []FlightLeg{{
+		Departure:  at(1, 6, 30).In(zurich),
+		Arrival:    at(1, 10, 0).In(london),
+		From:       zrh,
+		To:         lhr,
+		FromLatLng: zrh.LatLng,
+		ToLatLng:   lhr.LatLng,
+		Distance:   787.694 * unit.Kilometer,
+	}, {
+		Departure:  at(5, 18, 0).In(london),
+		Arrival:    at(5, 19, 30).In(zurich),
+		From:       lhr,
+		To:         zrh,
+		FromLatLng: lhr.LatLng,
+		ToLatLng:   zrh.LatLng,
+		Distance:   787.694 * unit.Kilometer,
+	}}
+	if diff := cmp.Diff(want, d.Flights(), toKilometers, cmpopts.EquateApprox(0, 0.001)); diff != "" {
+		t.Errorf("Flights() diff (-want +got):\n%s", diff)
+	}
+	for i, route := range []string{"ZRH-LHR", "LHR-ZRH"} {
+		if got := want[i].Route(); got != route {
+			t.Errorf("Route() = %q, want %q", got, route)
+		}
+	}
+}
+
+func TestFlightDetectorWithoutAirport(t *testing.T) {
+	d := NewFlightDetector(FlightOptions{})
+	for _, loc := range []reader.Location{
+		// A jump over the Atlantic, far from any airport, still in progress.
+		location(time.Date(2024, 4, 1, 8, 0, 0, 0, time.UTC), 30, -40),
+		location(time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC), 30, -30),
+	} {
+		if err := d.Add(loc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := d.Flights()
+	if len(got) != 1 {
+		t.Fatalf("Flights() = %v, want a single flight", got)
+	}
+	if want := "(30.00,-40.00)-(30.00,-30.00)"; got[0].Route() != want {
+		t.Errorf("Route() = %q, want %q", got[0].Route(), want)
+	}
+}
+
+func TestFlightDetectorJumps(t *testing.T) {
+	departure := time.Date(2024, 4, 1, 6, 0, 0, 0, time.UTC)
+	zurich := s2.LatLngFromDegrees(47.46, 8.55)
+	for _, tc := range []struct {
+		name     string
+		to       s2.LatLng
+		duration time.Duration
+		activity string
+		want     string
+	}{
+		{
+			// Phone turned off at home and turned on again at the hotel, at about 100km/h on average.
+			name:     "Door to door",
+			to:       s2.LatLngFromDegrees(51.47, -0.45),
+			duration: 8 * time.Hour,
+			want:     "ZRH-LHR",
+		},
+		{
+			// Phone turned off while driving to Paris with a night in between, at about 25km/h on average.
+			name:     "Overnight drive",
+			to:       s2.LatLngFromDegrees(48.72, 2.38),
+			duration: 20 * time.Hour,
+		},
+		{
+			name:     "Short and slow flight",
+			to:       s2.LatLngFromDegrees(48.35, 11.79),
+			duration: 2 * time.Hour,
+			activity: "FLYING",
+			want:     "ZRH-MUC",
+		},
+		{
+			name:     "Short and slow drive",
+			to:       s2.LatLngFromDegrees(48.35, 11.79),
+			duration: 2 * time.Hour,
+			activity: "IN_VEHICLE",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			arrival := location(departure.Add(tc.duration), tc.to.Lat.Degrees(), tc.to.Lng.Degrees())
+			if tc.activity != "" {
+				arrival.Activity = []reader.Activities{activities(arrival.Timestamp, reader.Activity{Type: tc.activity, Confidence: 80})}
+			}
+			d := NewFlightDetector(FlightOptions{})
+			for _, loc := range []reader.Location{location(departure, zurich.Lat.Degrees(), zurich.Lng.Degrees()), arrival} {
+				if err := d.Add(loc); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var got []string
+			for _, f := range d.Flights() {
+				got = append(got, f.Route())
+			}
+			var want []string
+			if tc.want != "" {
+				want = []string{tc.want}
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Flights() routes diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFlightDetectorSkipsLocationsOutOfOrder(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2024, 4, 1, hour, 0, 0, 0, time.UTC)
+	}
+	d := NewFlightDetector(FlightOptions{})
+	for _, tc := range []struct {
+		loc     reader.Location
+		wantErr bool
+	}{
+		{loc: location(at(6), 47.46, 8.55)},
+		{loc: location(at(10), 51.47, -0.45)},
+		// Recorded before landing, e.g. from another device. Comparing it with Heathrow would end the flight.
+		{loc: location(at(9), 51.0, 0), wantErr: true},
+		{loc: location(at(10), 51.47, -0.45)},
+		{loc: location(at(11), 51.51, -0.13)},
+	} {
+		if err := d.Add(tc.loc); (err != nil) != tc.wantErr {
+			t.Errorf("Add(%s) = %v, want error: %t", tc.loc.Timestamp, err, tc.wantErr)
+		}
+	}
+	got := d.Flights()
+	if len(got) != 1 || got[0].Route() != "ZRH-LHR" {
+		t.Errorf("Flights() = %v, want ZRH-LHR", got)
+	}
+}
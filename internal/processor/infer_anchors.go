@@ -0,0 +1,185 @@
+package processor
+
+import (
+	"slices"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+type InferOptions struct {
+	// Window is the duration over which the dominant location is determined, e.g. 30 days.
+	Window time.Duration
+	// MinWindows is the number of consecutive windows a new location must be dominant for to be considered a move.
+	// Shorter periods, e.g. long trips, are attributed to the previous location.
+	MinWindows int
+	// CellLevel is the level of the s2 cells locations are grouped by, see
+	// https://s2geometry.io/resources/s2cell_statistics. Level 15 cells are roughly 300m wide.
+	CellLevel int
+	// TimeZone used for determining night and office hours.
+	TimeZone *time.Location
+}
+
+// cellStats aggregates the locations within a cell.
+type cellStats struct {
+	days map[time.Time]bool
+	sum  s2.Point
+}
+
+// AnchorInferrer infers home and work anchors from a stream of locations:
+// * home is the location where most nights (22:00 to 06:00) are spent,
+// * work is the location other than home where most weekdays (09:00 to 17:00) are spent.
+// The dominant location is determined for every window, moves are detected as changes of the dominant location.
+type AnchorInferrer struct {
+	opts  InferOptions
+	night map[time.Time]map[s2.CellID]*cellStats
+	day   map[time.Time]map[s2.CellID]*cellStats
+}
+
+// NewAnchorInferrer creates an empty AnchorInferrer using the given options.
+func NewAnchorInferrer(opts InferOptions) *AnchorInferrer {
+	if opts.TimeZone == nil {
+		opts.TimeZone = time.UTC
+	}
+	return &AnchorInferrer{
+		opts:  opts,
+		night: make(map[time.Time]map[s2.CellID]*cellStats),
+		day:   make(map[time.Time]map[s2.CellID]*cellStats),
+	}
+}
+
+// Add counts the given location if it was recorded at night or during office hours. It returns an error if the
+// location's timestamp can not be parsed, in which case the location is ignored.
+func (a *AnchorInferrer) Add(loc reader.Location) error {
+	t, err := loc.ParsedTimestamp()
+	if err != nil {
+		return err
+	}
+	local := t.In(a.opts.TimeZone)
+	switch h := local.Hour(); {
+	case h >= 22 || h < 6:
+		// Nights are attributed to the day they started.
+		a.count(a.night, t, date(local.Add(-6*time.Hour)), loc)
+	case h >= 9 && h < 17 && local.Weekday() != time.Saturday && local.Weekday() != time.Sunday:
+		a.count(a.day, t, date(local), loc)
+	}
+	return nil
+}
+
+func date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (a *AnchorInferrer) count(windows map[time.Time]map[s2.CellID]*cellStats, t, day time.Time, loc reader.Location) {
+	w := t.Truncate(a.opts.Window)
+	if windows[w] == nil {
+		windows[w] = make(map[s2.CellID]*cellStats)
+	}
+	latlng := loc.LatLng()
+	p := s2.PointFromLatLng(latlng)
+	c := s2.CellIDFromLatLng(latlng).Parent(a.opts.CellLevel)
+	stats := windows[w][c]
+	if stats == nil {
+		stats = &cellStats{days: make(map[time.Time]bool)}
+		windows[w][c] = stats
+	}
+	stats.days[day] = true
+	stats.sum = s2.Point{Vector: stats.sum.Add(p.Vector)}
+}
+
+// dominantCell is the cell where most days were spent within a window.
+type dominantCell struct {
+	window time.Time
+	cell   s2.CellID
+	sum    s2.Point
+}
+
+// dominantCells returns the dominant cell of every window, ordered by time. Cells for which exclude returns true
+// are not considered.
+func dominantCells(windows map[time.Time]map[s2.CellID]*cellStats, exclude func(time.Time, s2.CellID) bool) []dominantCell {
+	res := make([]dominantCell, 0, len(windows))
+	for w, cells := range windows {
+		var best s2.CellID
+		bestDays := 0
+		for c, stats := range cells {
+			if exclude(w, c) {
+				continue
+			}
+			if n := len(stats.days); n > bestDays || (n == bestDays && c < best) {
+				best, bestDays = c, n
+			}
+		}
+		if bestDays == 0 {
+			continue
+		}
+		res = append(res, dominantCell{window: w, cell: best, sum: cells[best].sum})
+	}
+	slices.SortFunc(res, func(a, b dominantCell) int {
+		return a.window.Compare(b.window)
+	})
+	return res
+}
+
+// toAnchors combines consecutive windows with the same dominant cell to anchors. Runs shorter than MinWindows
+// are attributed to the previous anchor.
+func (a *AnchorInferrer) toAnchors(cells []dominantCell) []Anchor {
+	type run struct {
+		start time.Time
+		cell  s2.CellID
+		sum   s2.Point
+		count int
+	}
+	runs := make([]run, 0)
+	for _, c := range cells {
+		if len(runs) > 0 && runs[len(runs)-1].cell == c.cell {
+			r := &runs[len(runs)-1]
+			r.sum = s2.Point{Vector: r.sum.Add(c.sum.Vector)}
+			r.count++
+			continue
+		}
+		runs = append(runs, run{start: c.window, cell: c.cell, sum: c.sum, count: 1})
+	}
+	res := make([]Anchor, 0, len(runs))
+	var last s2.CellID
+	for i, r := range runs {
+		if i > 0 && (r.count < a.opts.MinWindows || r.cell == last) {
+			continue
+		}
+		last = r.cell
+		res = append(res, Anchor{
+			StartTime: r.start,
+			Location:  s2.LatLngFromPoint(s2.Point{Vector: r.sum.Normalize()}),
+		})
+	}
+	return res
+}
+
+// Home returns the inferred home anchors, ordered by StartTime.
+func (a *AnchorInferrer) Home() []Anchor {
+	return a.toAnchors(a.homeCells())
+}
+
+func (a *AnchorInferrer) homeCells() []dominantCell {
+	return dominantCells(a.night, func(time.Time, s2.CellID) bool { return false })
+}
+
+// Work returns the inferred work anchors, ordered by StartTime. The home cell of a window is never chosen as work.
+func (a *AnchorInferrer) Work() []Anchor {
+	home := make(map[time.Time]s2.CellID)
+	for _, c := range a.homeCells() {
+		home[c.window] = c.cell
+	}
+	return a.toAnchors(dominantCells(a.day, func(w time.Time, c s2.CellID) bool {
+		return home[w] == c
+	}))
+}
+
+// InferAnchors infers home and work anchors from the given locations, see AnchorInferrer.
+func InferAnchors(locations []reader.Location, opts InferOptions) (home, work []Anchor) {
+	a := NewAnchorInferrer(opts)
+	for _, loc := range locations {
+		a.Add(loc)
+	}
+	return a.Home(), a.Work()
+}
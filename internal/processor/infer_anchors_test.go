@@ -0,0 +1,60 @@
+package processor
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+func location(t time.Time, lat, lng float64) reader.Location {
+	return reader.Location{
+		Timestamp:   t.Format(time.RFC3339),
+		LatitudeE7:  int(math.Round(lat * 1e7)),
+		LongitudeE7: int(math.Round(lng * 1e7)),
+	}
+}
+
+func TestInferAnchors(t *testing.T) {
+	window := 30 * 24 * time.Hour
+	start := time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC).Truncate(window)
+	homeA, homeB, trip := s2.LatLngFromDegrees(47, 8), s2.LatLngFromDegrees(37.7, -122.4), s2.LatLngFromDegrees(35.7, 139.7)
+	workA, workB := s2.LatLngFromDegrees(47.2, 8.1), s2.LatLngFromDegrees(37.4, -122.1)
+	var locations []reader.Location
+	for day := 0; day < 9*30; day++ {
+		home, work := homeA, workA
+		switch w := day / 30; {
+		case w == 3:
+			home, work = trip, trip
+		case w >= 6:
+			home, work = homeB, workB
+		}
+		t := start.AddDate(0, 0, day)
+		locations = append(locations,
+			location(t.Add(23*time.Hour), home.Lat.Degrees(), home.Lng.Degrees()),
+			location(t.Add(8*time.Hour), home.Lat.Degrees(), home.Lng.Degrees()),
+		)
+		if t.Weekday() != time.Saturday && t.Weekday() != time.Sunday {
+			locations = append(locations, location(t.Add(12*time.Hour), work.Lat.Degrees(), work.Lng.Degrees()))
+		}
+	}
+
+	home, work := InferAnchors(locations, InferOptions{Window: window, MinWindows: 2, CellLevel: 15})
+	wantHome := []Anchor{
+		{StartTime: start, Location: homeA},
+		{StartTime: start.Add(6 * window), Location: homeB},
+	}
+	if diff := cmp.Diff(home, wantHome, approxLatLng); diff != "" {
+		t.Errorf("InferAnchors() home = %v, want %v. Diff: %v", home, wantHome, diff)
+	}
+	wantWork := []Anchor{
+		{StartTime: start, Location: workA},
+		{StartTime: start.Add(6 * window), Location: workB},
+	}
+	if diff := cmp.Diff(work, wantWork, approxLatLng); diff != "" {
+		t.Errorf("InferAnchors() work = %v, want %v. Diff: %v", work, wantWork, diff)
+	}
+}
@@ -0,0 +1,168 @@
+package processor
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang/geo/earth"
+	"github.com/golang/geo/s2"
+	"github.com/google/go-units/unit"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+type PathLengthOptions struct {
+	// MinSegment is the minimum length of a segment to count, in addition to the accuracy of its fixes. Useful for
+	// inputs without accuracy, e.g. GPX tracks.
+	MinSegment unit.Length
+	// TimeZone used for splitting days. Defaults to UTC.
+	TimeZone *time.Location
+	// TimeZoneAt optionally overrides TimeZone with the local time where each location was recorded.
+	TimeZoneAt ZoneFunc
+}
+
+// fix is a location with its time and accuracy.
+type fix struct {
+	t        time.Time
+	latlng   s2.LatLng
+	accuracy unit.Length
+}
+
+func newFix(t time.Time, loc reader.Location) fix {
+	return fix{t: t, latlng: loc.LatLng(), accuracy: unit.Length(loc.Accuracy) * unit.Meter}
+}
+
+// smoother measures the segments between consecutive fixes. Movements within the accuracy of both fixes are treated
+// as jitter.
+type smoother struct {
+	minSegment unit.Length
+	// last is the end of the last counted segment.
+	last *fix
+	// latest is the time of the latest fix passed to next.
+	latest time.Time
+}
+
+// checkOrder returns an error if a fix at time t is older than the latest fix, in which case it must be skipped.
+// Measuring from an older fix would add a bogus segment back and forth.
+func checkOrder(t, latest time.Time) error {
+	if t.Before(latest) {
+		return fmt.Errorf("skipping location at %s older than the previous one at %s", t.Format(time.RFC3339), latest.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// next returns the segment from the end of the last counted segment to the given fix. Returns false for the first
+// fix and for jitter. Jitter that is more accurate than the last fix replaces it as start of the next segment.
+func (s *smoother) next(f fix) (from fix, length unit.Length, ok bool) {
+	s.latest = f.t
+	if s.last == nil {
+		s.last = &f
+		return fix{}, 0, false
+	}
+	from = *s.last
+	length = earth.LengthFromAngle(from.latlng.Distance(f.latlng))
+	if length <= max(from.accuracy, f.accuracy, s.minSegment) {
+		if f.accuracy < from.accuracy {
+			s.last = &f
+		}
+		return fix{}, 0, false
+	}
+	s.last = &f
+	return from, length, true
+}
+
+// localDate returns midnight of the date of t in the given time zone.
+func localDate(t time.Time, tz *time.Location) time.Time {
+	l := t.In(tz)
+	return time.Date(l.Year(), l.Month(), l.Day(), 0, 0, 0, 0, tz)
+}
+
+// PathLengthBucketer sums the distance traveled per day from a stream of locations, i.e. the great-circle lengths of
+// the segments between consecutive fixes. Movements within the accuracy of the fixes are treated as jitter and
+// skipped, so the total does not grow while standing still.
+// Locations are expected to be added in ascending order of time.
+type PathLengthBucketer struct {
+	opts PathLengthOptions
+	// Buckets keyed by their UTC time, as the same instant in different time zones results in different map keys.
+	buckets  map[time.Time]DistanceByTimeBucket
+	smoother smoother
+}
+
+// NewPathLengthBucketer creates an empty PathLengthBucketer using the given options.
+func NewPathLengthBucketer(opts PathLengthOptions) *PathLengthBucketer {
+	if opts.TimeZone == nil {
+		opts.TimeZone = time.UTC
+	}
+	return &PathLengthBucketer{
+		opts:     opts,
+		buckets:  make(map[time.Time]DistanceByTimeBucket, 365),
+		smoother: smoother{minSegment: opts.MinSegment},
+	}
+}
+
+// Add adds the segment from the previous location to the given one to the day of the given location. It returns an
+// error if the location's timestamp can not be parsed or is older than the previous one, in which case the location
+// is ignored.
+func (p *PathLengthBucketer) Add(loc reader.Location) error {
+	t, err := loc.ParsedTimestamp()
+	if err != nil {
+		return err
+	}
+	if err := checkOrder(t, p.smoother.latest); err != nil {
+		return err
+	}
+	latlng := loc.LatLng()
+	tz := zoneAt(p.opts.TimeZone, p.opts.TimeZoneAt, latlng)
+	day := localDate(t, tz)
+	b, ok := p.buckets[day.UTC()]
+	if !ok {
+		b = DistanceByTimeBucket{Bucket: day}
+	}
+	if _, length, ok := p.smoother.next(newFix(t, loc)); ok {
+		b.Distance += length
+	}
+	p.buckets[day.UTC()] = b
+	return nil
+}
+
+// Buckets returns the distance traveled on every day seen so far, ordered by time.
+func (p *PathLengthBucketer) Buckets() []DistanceByTimeBucket {
+	res := make([]DistanceByTimeBucket, 0, len(p.buckets))
+	for _, b := range p.buckets {
+		res = append(res, b)
+	}
+	slices.SortFunc(res, func(a, b DistanceByTimeBucket) int {
+		return a.Bucket.Compare(b.Bucket)
+	})
+	return res
+}
+
+// sumBuckets sums the distances of buckets with the same start according to the given function. Starts are compared
+// by date, so days in different time zones are summed as well. Assumes buckets are ordered by time.
+func sumBuckets(items []DistanceByTimeBucket, start func(time.Time) time.Time) []DistanceByTimeBucket {
+	var res []DistanceByTimeBucket
+	for _, d := range items {
+		s := start(d.Bucket)
+		if len(res) == 0 || res[len(res)-1].Bucket.Format(time.DateOnly) != s.Format(time.DateOnly) {
+			res = append(res, DistanceByTimeBucket{Bucket: s})
+		}
+		res[len(res)-1].Distance += d.Distance
+	}
+	return res
+}
+
+// SumWeekly sums daily buckets to weeks starting on Monday, e.g. for the distance traveled per week.
+func SumWeekly(items []DistanceByTimeBucket) []DistanceByTimeBucket {
+	return sumBuckets(items, func(t time.Time) time.Time {
+		// Days since Monday.
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+	})
+}
+
+// SumYearly sums daily buckets to calendar years, e.g. for the distance traveled per year.
+func SumYearly(items []DistanceByTimeBucket) []DistanceByTimeBucket {
+	return sumBuckets(items, func(t time.Time) time.Time {
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	})
+}
@@ -0,0 +1,73 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-units/unit"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+func TestPathLengthBucketer(t *testing.T) {
+	withAccuracy := func(loc reader.Location, accuracy int) reader.Location {
+		loc.Accuracy = accuracy
+		return loc
+	}
+	at := func(day, hour, minute int) time.Time { return time.Date(2024, 4, day, hour, minute, 0, 0, time.UTC) }
+	locations := []reader.Location{
+		withAccuracy(location(at(1, 8, 0), 47, 8), 10),
+		// Jitter of 11m, within the accuracy.
+		withAccuracy(location(at(1, 8, 5), 47.0001, 8), 50),
+		withAccuracy(location(at(1, 9, 0), 48, 8), 10),
+		// Jitter, but more accurate than the previous fix, so the next segment starts here.
+		withAccuracy(location(at(1, 9, 10), 48.00005, 8), 5),
+		withAccuracy(location(at(2, 10, 0), 47, 8), 10),
+		// Next week, without accuracy.
+		location(at(8, 10, 0), 47, 9),
+	}
+	b := NewPathLengthBucketer(PathLengthOptions{})
+	for _, loc := range locations {
+		if err := b.Add(loc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	daily := b.Buckets()
+	for _, tc := range []struct {
+		name string
+		got  []DistanceByTimeBucket
+		want []DistanceByTimeBucket
+	}{
+		{
+			name: "Daily",
+			got:  daily,
+			want: []DistanceByTimeBucket{
+				{Distance: 111.195 * unit.Kilometer, Bucket: parseDate(t, "2024-04-01")},
+				{Distance: 111.201 * unit.Kilometer, Bucket: parseDate(t, "2024-04-02")},
+				{Distance: 75.834 * unit.Kilometer, Bucket: parseDate(t, "2024-04-08")},
+			},
+		},
+		{
+			name: "Weekly",
+			got:  SumWeekly(daily),
+			want: []DistanceByTimeBucket{
+				{Distance: 222.396 * unit.Kilometer, Bucket: parseDate(t, "2024-04-01")},
+				{Distance: 75.834 * unit.Kilometer, Bucket: parseDate(t, "2024-04-08")},
+			},
+		},
+		{
+			name: "Yearly",
+			got:  SumYearly(daily),
+			want: []DistanceByTimeBucket{
+				{Distance: 298.230 * unit.Kilometer, Bucket: parseDate(t, "2024-01-01")},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.got, toKilometers, cmpopts.EquateApprox(0, 0.01)); diff != "" {
+				t.Errorf("diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
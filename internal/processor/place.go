@@ -0,0 +1,214 @@
+package processor
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/golang/geo/earth"
+	"github.com/golang/geo/s1"
+	"github.com/golang/geo/s2"
+	"github.com/google/go-units/unit"
+)
+
+// Place is a significant place, found by clustering stays.
+type Place struct {
+	// ID is derived from the centroid, so it stays the same when clustering similar data again.
+	ID string
+	// Name is a label given by the user, e.g. "Gym".
+	Name     string
+	Centroid s2.LatLng
+	// Radius is the distance from the centroid to the farthest stay of the place plus the clustering epsilon,
+	// so it covers all points that would be clustered into the place.
+	Radius unit.Length
+	Visits int
+	Dwell  time.Duration
+}
+
+// placeJson is the representation of a Place in a places file.
+type placeJson struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	Lat          float64 `json:"lat"`
+	Lng          float64 `json:"lng"`
+	RadiusMeters float64 `json:"radiusMeters"`
+	Visits       int     `json:"visits"`
+	DwellHours   float64 `json:"dwellHours"`
+}
+
+func (p Place) MarshalJSON() ([]byte, error) {
+	return json.Marshal(placeJson{
+		ID:           p.ID,
+		Name:         p.Name,
+		Lat:          p.Centroid.Lat.Degrees(),
+		Lng:          p.Centroid.Lng.Degrees(),
+		RadiusMeters: p.Radius.Meters(),
+		Visits:       p.Visits,
+		DwellHours:   p.Dwell.Hours(),
+	})
+}
+
+func (p *Place) UnmarshalJSON(data []byte) error {
+	var pj placeJson
+	if err := json.Unmarshal(data, &pj); err != nil {
+		return err
+	}
+	*p = Place{
+		ID:       pj.ID,
+		Name:     pj.Name,
+		Centroid: s2.LatLngFromDegrees(pj.Lat, pj.Lng),
+		Radius:   unit.Length(pj.RadiusMeters) * unit.Meter,
+		Visits:   pj.Visits,
+		Dwell:    time.Duration(pj.DwellHours * float64(time.Hour)),
+	}
+	return nil
+}
+
+// Contains returns true if the given point is within the radius of the place.
+func (p Place) Contains(latlng s2.LatLng) bool {
+	return earth.LengthFromAngle(p.Centroid.Distance(latlng)) <= p.Radius
+}
+
+type ClusterOptions struct {
+	// Epsilon is the maximum distance between two stays to be considered neighbors.
+	Epsilon unit.Length
+	// MinStays is the minimum number of neighbors, including the stay itself, for a stay to be a core point of a place.
+	MinStays int
+}
+
+// stayIndex allows looking up stays by s2 cells, whose size is chosen such that all neighbors of a stay are
+// within the stay's cell or one of the adjacent cells.
+type stayIndex struct {
+	level   int
+	epsilon s1.Angle
+	cells   map[s2.CellID][]int
+	stays   []StayPoint
+}
+
+func newStayIndex(stays []StayPoint, epsilon unit.Length) stayIndex {
+	angle := earth.AngleFromLength(epsilon)
+	idx := stayIndex{
+		level:   s2.MinWidthMetric.MaxLevel(angle.Radians()),
+		epsilon: angle,
+		cells:   make(map[s2.CellID][]int),
+		stays:   stays,
+	}
+	for i, s := range stays {
+		c := s2.CellIDFromLatLng(s.Centroid).Parent(idx.level)
+		idx.cells[c] = append(idx.cells[c], i)
+	}
+	return idx
+}
+
+// neighbors returns the indices of all stays within epsilon of the given stay, including itself.
+func (idx stayIndex) neighbors(i int) []int {
+	c := s2.CellIDFromLatLng(idx.stays[i].Centroid).Parent(idx.level)
+	res := make([]int, 0)
+	for _, n := range append(c.AllNeighbors(idx.level), c) {
+		for _, j := range idx.cells[n] {
+			if idx.stays[i].Centroid.Distance(idx.stays[j].Centroid) <= idx.epsilon {
+				res = append(res, j)
+			}
+		}
+	}
+	return res
+}
+
+// ClusterPlaces groups stays into places using DBSCAN, see https://en.wikipedia.org/wiki/DBSCAN.
+// Stays that are not part of any cluster are dropped. Places are ordered by total dwell time, descending.
+func ClusterPlaces(stays []StayPoint, opts ClusterOptions) []Place {
+	const unvisited, noise = 0, -1
+	idx := newStayIndex(stays, opts.Epsilon)
+	labels := make([]int, len(stays))
+	cluster := 0
+	for i := range stays {
+		if labels[i] != unvisited {
+			continue
+		}
+		neighbors := idx.neighbors(i)
+		if len(neighbors) < opts.MinStays {
+			labels[i] = noise
+			continue
+		}
+		cluster++
+		labels[i] = cluster
+		for len(neighbors) > 0 {
+			j := neighbors[0]
+			neighbors = neighbors[1:]
+			if labels[j] == noise {
+				// Border point, reachable but not a core point.
+				labels[j] = cluster
+			}
+			if labels[j] != unvisited {
+				continue
+			}
+			labels[j] = cluster
+			if n := idx.neighbors(j); len(n) >= opts.MinStays {
+				neighbors = append(neighbors, n...)
+			}
+		}
+	}
+
+	members := make([][]StayPoint, cluster)
+	for i, l := range labels {
+		if l > 0 {
+			members[l-1] = append(members[l-1], stays[i])
+		}
+	}
+	res := make([]Place, 0, cluster)
+	for _, m := range members {
+		res = append(res, newPlace(m, opts.Epsilon))
+	}
+	slices.SortStableFunc(res, func(a, b Place) int {
+		return cmp.Compare(b.Dwell, a.Dwell)
+	})
+	return res
+}
+
+func newPlace(stays []StayPoint, epsilon unit.Length) Place {
+	points := make([]timedLatLng, 0, len(stays))
+	var dwell time.Duration
+	for _, s := range stays {
+		points = append(points, timedLatLng{latlng: s.Centroid})
+		dwell += s.Dwell()
+	}
+	c := centroid(points)
+	radius := epsilon
+	for _, s := range stays {
+		radius = max(radius, earth.LengthFromAngle(c.Distance(s.Centroid))+epsilon)
+	}
+	return Place{
+		ID:       s2.CellIDFromLatLng(c).Parent(20).ToToken(),
+		Centroid: c,
+		Radius:   radius,
+		Visits:   len(stays),
+		Dwell:    dwell,
+	}
+}
+
+// KeepNames copies names of previously labeled places to the places containing their centroid. This allows
+// re-clustering with new data without losing labels given by the user.
+func KeepNames(places, labeled []Place) {
+	for _, l := range labeled {
+		if l.Name == "" {
+			continue
+		}
+		for i := range places {
+			if places[i].ID == l.ID || places[i].Contains(l.Centroid) {
+				places[i].Name = l.Name
+				break
+			}
+		}
+	}
+}
+
+// PlaceAt returns the first place containing the given point.
+func PlaceAt(places []Place, latlng s2.LatLng) (Place, bool) {
+	for _, p := range places {
+		if p.Contains(latlng) {
+			return p, true
+		}
+	}
+	return Place{}, false
+}
@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-units/unit"
+)
+
+func stay(lat, lng float64, dwell time.Duration) StayPoint {
+	arrival := time.Date(2014, 4, 1, 8, 0, 0, 0, time.UTC)
+	return StayPoint{Centroid: s2.LatLngFromDegrees(lat, lng), Arrival: arrival, Departure: arrival.Add(dwell)}
+}
+
+func TestClusterPlaces(t *testing.T) {
+	stays := []StayPoint{
+		// Home, stays about 20m apart.
+		stay(47, 8, 10*time.Hour),
+		stay(47.0002, 8, 12*time.Hour),
+		stay(47.0004, 8, 8*time.Hour),
+		// Gym.
+		stay(47.1, 8, time.Hour),
+		stay(47.1001, 8, time.Hour),
+		// A single stay is noise.
+		stay(46, 7, time.Hour),
+	}
+	got := ClusterPlaces(stays, ClusterOptions{Epsilon: 50 * unit.Meter, MinStays: 2})
+	want := []Place{
+		{Centroid: s2.LatLngFromDegrees(47.0002, 8), Radius: 72.239 * unit.Meter, Visits: 3, Dwell: 30 * time.Hour},
+		{Centroid: s2.LatLngFromDegrees(47.10005, 8), Radius: 55.560 * unit.Meter, Visits: 2, Dwell: 2 * time.Hour},
+	}
+	toMeters := cmp.Transformer("toMeters", func(d unit.Length) float64 { return d.Meters() })
+	if diff := cmp.Diff(got, want, toMeters, approxLatLng, cmpopts.EquateApprox(0, 0.01), cmpopts.IgnoreFields(Place{}, "ID")); diff != "" {
+		t.Errorf("ClusterPlaces() = %v, want %v. Diff: %v", got, want, diff)
+	}
+	if got[0].ID == "" || got[0].ID == got[1].ID {
+		t.Errorf("ClusterPlaces() IDs = %q, %q, want distinct non-empty IDs", got[0].ID, got[1].ID)
+	}
+}
+
+func TestPlaceJsonRoundTrip(t *testing.T) {
+	want := []Place{{
+		ID:       "478fde",
+		Name:     "Gym",
+		Centroid: s2.LatLngFromDegrees(47.1, 8),
+		Radius:   55 * unit.Meter,
+		Visits:   2,
+		Dwell:    90 * time.Minute,
+	}}
+	b, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("json.Marshal() = %v", err)
+	}
+	var got []Place
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("json.Unmarshal() = %v", err)
+	}
+	if diff := cmp.Diff(got, want, approxLatLng, cmpopts.EquateApprox(0, 1e-9)); diff != "" {
+		t.Errorf("json.Unmarshal(json.Marshal()) = %v, want %v. Diff: %v", got, want, diff)
+	}
+}
+
+func TestKeepNames(t *testing.T) {
+	places := []Place{
+		{ID: "a", Centroid: s2.LatLngFromDegrees(47, 8), Radius: 100 * unit.Meter},
+		{ID: "b", Centroid: s2.LatLngFromDegrees(47.1, 8), Radius: 100 * unit.Meter},
+	}
+	labeled := []Place{
+		{ID: "old", Name: "Home", Centroid: s2.LatLngFromDegrees(47.0001, 8)},
+		{ID: "b", Name: "Gym"},
+	}
+	KeepNames(places, labeled)
+	if places[0].Name != "Home" || places[1].Name != "Gym" {
+		t.Errorf("KeepNames() = %v, want names Home and Gym", places)
+	}
+	if p, ok := PlaceAt(places, s2.LatLngFromDegrees(47.1, 8.0001)); !ok || p.Name != "Gym" {
+		t.Errorf("PlaceAt() = %v, %t, want Gym", p, ok)
+	}
+}
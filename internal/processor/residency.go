@@ -0,0 +1,235 @@
+package processor
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/panmari/locationhistory/internal/geo"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+// ResidencyRule decides which countries a day counts for.
+type ResidencyRule int
+
+const (
+	// AnyPresence counts a day for every country with at least one location on that day.
+	AnyPresence ResidencyRule = iota
+	// Midnight counts a day only for the country of the last location before midnight at the end of the day.
+	// Days without locations count for the country of the last location of an earlier day, up to
+	// ResidencyOptions.MaxCarryDays.
+	Midnight
+)
+
+// Evidence is a location supporting that a day counts for a country.
+type Evidence struct {
+	// Country as ISO 3166-1 alpha-2 code, empty if the location is at sea.
+	Country string
+	// Region as ISO 3166-2 code.
+	Region string
+	Time   time.Time
+	LatLng s2.LatLng
+}
+
+// ResidencyDay lists the countries a day counts for, together with the evidence for each.
+type ResidencyDay struct {
+	Day       time.Time
+	Countries []string
+	Evidence  []Evidence
+	// Carried is true if there is no location on the day, so the evidence is the last location of an earlier day.
+	Carried bool
+}
+
+// ResidencyYear is the number of days counted for each country in a calendar or fiscal year.
+type ResidencyYear struct {
+	// First and last day of the year.
+	Start time.Time
+	End   time.Time
+	// Countries ordered by the number of days counted, descending.
+	Countries []CountryVisit
+}
+
+type ResidencyOptions struct {
+	Rule ResidencyRule
+	// Geocode returns the region at the given coordinates. Defaults to geo.ReverseGeocode.
+	Geocode func(s2.LatLng) (geo.Region, bool)
+	// TimeZone used for determining days. Defaults to UTC.
+	TimeZone *time.Location
+	// TimeZoneAt optionally overrides TimeZone with the local time where each location was recorded.
+	TimeZoneAt ZoneFunc
+	// MaxCarryDays is the maximum number of consecutive days without locations that count for the country of the last
+	// location before them with the Midnight rule. Further days of a gap, e.g. with the phone turned off for months,
+	// don't count for any country. Defaults to 7, negative values disable carrying days over.
+	MaxCarryDays int
+	// YearStartMonth and YearStartDay give the first day of a fiscal year, e.g. April 6 in the UK. Defaults to
+	// January 1, i.e. calendar years.
+	YearStartMonth time.Month
+	YearStartDay   int
+}
+
+// residencyDay aggregates the locations of a single day.
+type residencyDay struct {
+	// first location in each country, used for AnyPresence.
+	first map[string]Evidence
+	// last location of the day, used for Midnight.
+	last Evidence
+}
+
+// ResidencyCounter counts the days spent in each country for tax or visa purposes from a stream of locations.
+// Locations may be added in any order.
+type ResidencyCounter struct {
+	opts ResidencyOptions
+	days map[time.Time]*residencyDay
+}
+
+// NewResidencyCounter creates an empty ResidencyCounter using the given options.
+func NewResidencyCounter(opts ResidencyOptions) *ResidencyCounter {
+	if opts.Geocode == nil {
+		opts.Geocode = geo.ReverseGeocode
+	}
+	if opts.TimeZone == nil {
+		opts.TimeZone = time.UTC
+	}
+	if opts.MaxCarryDays == 0 {
+		opts.MaxCarryDays = 7
+	}
+	if opts.YearStartMonth == 0 {
+		opts.YearStartMonth = time.January
+	}
+	if opts.YearStartDay == 0 {
+		opts.YearStartDay = 1
+	}
+	return &ResidencyCounter{
+		opts: opts,
+		days: make(map[time.Time]*residencyDay, 365),
+	}
+}
+
+// Add records the given location. It returns an error if the location's timestamp can not be parsed, in which case
+// the location is ignored.
+func (r *ResidencyCounter) Add(loc reader.Location) error {
+	t, err := loc.ParsedTimestamp()
+	if err != nil {
+		return err
+	}
+	latlng := loc.LatLng()
+	tz := zoneAt(r.opts.TimeZone, r.opts.TimeZoneAt, latlng)
+	day := date(t.In(tz))
+	d := r.days[day]
+	if d == nil {
+		d = &residencyDay{first: make(map[string]Evidence)}
+		r.days[day] = d
+	}
+	switch r.opts.Rule {
+	case AnyPresence:
+		e := r.evidence(t, latlng)
+		if prev, ok := d.first[e.Country]; e.Country != "" && (!ok || t.Before(prev.Time)) {
+			d.first[e.Country] = e
+		}
+	case Midnight:
+		// Geocoding is deferred to Days, as only the last location of each day is needed.
+		if d.last.Time.IsZero() || t.After(d.last.Time) {
+			d.last = Evidence{Time: t, LatLng: latlng}
+		}
+	}
+	return nil
+}
+
+func (r *ResidencyCounter) evidence(t time.Time, latlng s2.LatLng) Evidence {
+	region, _ := r.opts.Geocode(latlng)
+	return Evidence{Country: region.Country, Region: region.Code, Time: t, LatLng: latlng}
+}
+
+// Days returns the countries every day counts for, ordered by time. With the Midnight rule, all days between the first
+// and the last location are returned, except for days further than MaxCarryDays into a gap without locations.
+func (r *ResidencyCounter) Days() []ResidencyDay {
+	days := make([]time.Time, 0, len(r.days))
+	for day := range r.days {
+		days = append(days, day)
+	}
+	slices.SortFunc(days, time.Time.Compare)
+	if r.opts.Rule == Midnight {
+		return r.midnightDays(days)
+	}
+	res := make([]ResidencyDay, 0, len(days))
+	for _, day := range days {
+		d := ResidencyDay{Day: day}
+		for _, e := range r.days[day].first {
+			d.Evidence = append(d.Evidence, e)
+		}
+		if len(d.Evidence) == 0 {
+			continue
+		}
+		slices.SortFunc(d.Evidence, func(a, b Evidence) int {
+			return a.Time.Compare(b.Time)
+		})
+		for _, e := range d.Evidence {
+			d.Countries = append(d.Countries, e.Country)
+		}
+		res = append(res, d)
+	}
+	return res
+}
+
+func (r *ResidencyCounter) midnightDays(days []time.Time) []ResidencyDay {
+	if len(days) == 0 {
+		return nil
+	}
+	var res []ResidencyDay
+	var last Evidence
+	// carried counts the days since the last day with locations.
+	carried := 0
+	for day := days[0]; !day.After(days[len(days)-1]); day = day.AddDate(0, 0, 1) {
+		if d, ok := r.days[day]; ok {
+			last = r.evidence(d.last.Time, d.last.LatLng)
+			carried = 0
+		} else {
+			carried++
+			if carried > r.opts.MaxCarryDays {
+				continue
+			}
+		}
+		rd := ResidencyDay{Day: day, Evidence: []Evidence{last}, Carried: carried > 0}
+		if last.Country != "" {
+			rd.Countries = []string{last.Country}
+		}
+		res = append(res, rd)
+	}
+	return res
+}
+
+// yearStart returns the first day of the calendar or fiscal year containing the given day.
+func (r *ResidencyCounter) yearStart(day time.Time) time.Time {
+	start := time.Date(day.Year(), r.opts.YearStartMonth, r.opts.YearStartDay, 0, 0, 0, 0, time.UTC)
+	if day.Before(start) {
+		start = start.AddDate(-1, 0, 0)
+	}
+	return start
+}
+
+// Years counts the days for each country per calendar or fiscal year.
+func (r *ResidencyCounter) Years() []ResidencyYear {
+	var res []ResidencyYear
+	for _, d := range r.Days() {
+		start := r.yearStart(d.Day)
+		if len(res) == 0 || !res[len(res)-1].Start.Equal(start) {
+			res = append(res, ResidencyYear{Start: start, End: start.AddDate(1, 0, -1)})
+		}
+		y := &res[len(res)-1]
+		for _, country := range d.Countries {
+			i := slices.IndexFunc(y.Countries, func(v CountryVisit) bool { return v.Country == country })
+			if i < 0 {
+				y.Countries = append(y.Countries, CountryVisit{Country: country})
+				i = len(y.Countries) - 1
+			}
+			y.Countries[i].Days++
+		}
+	}
+	for _, y := range res {
+		slices.SortFunc(y.Countries, func(a, b CountryVisit) int {
+			return cmp.Or(b.Days-a.Days, cmp.Compare(a.Country, b.Country))
+		})
+	}
+	return res
+}
@@ -0,0 +1,101 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+func TestResidencyCounter(t *testing.T) {
+	zurich, loerrach := s2.LatLngFromDegrees(47.3769, 8.5417), s2.LatLngFromDegrees(47.6156, 7.6614)
+	at := func(day, hour int) time.Time { return time.Date(2024, 4, day, hour, 0, 0, 0, time.UTC) }
+	locations := []reader.Location{
+		location(at(4, 10), 47.3769, 8.5417),
+		location(at(4, 22), 47.6156, 7.6614),
+		// No locations on April 5.
+		// Added out of order.
+		location(at(6, 12), 47.3769, 8.5417),
+		location(at(6, 8), 47.6156, 7.6614),
+		location(at(7, 12), 47.3769, 8.5417),
+		// Atlantic, which does not count for any country.
+		location(at(7, 13), 30, -40),
+	}
+	chEvidence := func(t time.Time) Evidence {
+		return Evidence{Country: "CH", Region: "CH-ZH", Time: t, LatLng: zurich}
+	}
+	deEvidence := func(t time.Time) Evidence {
+		return Evidence{Country: "DE", Region: "DE-BW", Time: t, LatLng: loerrach}
+	}
+	for _, tc := range []struct {
+		name      string
+		opts      ResidencyOptions
+		wantDays  []ResidencyDay
+		wantYears []ResidencyYear
+	}{
+		{
+			name: "Any presence, calendar year",
+			opts: ResidencyOptions{Rule: AnyPresence},
+			wantDays: []ResidencyDay{
+				{Day: parseDate(t, "2024-04-04"), Countries: []string{"CH", "DE"}, Evidence: []Evidence{chEvidence(at(4, 10)), deEvidence(at(4, 22))}},
+				{Day: parseDate(t, "2024-04-06"), Countries: []string{"DE", "CH"}, Evidence: []Evidence{deEvidence(at(6, 8)), chEvidence(at(6, 12))}},
+				{Day: parseDate(t, "2024-04-07"), Countries: []string{"CH"}, Evidence: []Evidence{chEvidence(at(7, 12))}},
+			},
+			wantYears: []ResidencyYear{{
+				Start:     parseDate(t, "2024-01-01"),
+				End:       parseDate(t, "2024-12-31"),
+				Countries: []CountryVisit{{Country: "CH", Days: 3}, {Country: "DE", Days: 2}},
+			}},
+		},
+		{
+			name: "Midnight, fiscal year",
+			opts: ResidencyOptions{Rule: Midnight, YearStartMonth: time.April, YearStartDay: 6},
+			wantDays: []ResidencyDay{
+				{Day: parseDate(t, "2024-04-04"), Countries: []string{"DE"}, Evidence: []Evidence{deEvidence(at(4, 22))}},
+				{Day: parseDate(t, "2024-04-05"), Countries: []string{"DE"}, Evidence: []Evidence{deEvidence(at(4, 22))}, Carried: true},
+				{Day: parseDate(t, "2024-04-06"), Countries: []string{"CH"}, Evidence: []Evidence{chEvidence(at(6, 12))}},
+				{Day: parseDate(t, "2024-04-07"), Evidence: []Evidence{{Time: at(7, 13), LatLng: s2.LatLngFromDegrees(30, -40)}}},
+			},
+			wantYears: []ResidencyYear{{
+				Start:     parseDate(t, "2023-04-06"),
+				End:       parseDate(t, "2024-04-05"),
+				Countries: []CountryVisit{{Country: "DE", Days: 2}},
+			}, {
+				Start:     parseDate(t, "2024-04-06"),
+				End:       parseDate(t, "2025-04-05"),
+				Countries: []CountryVisit{{Country: "CH", Days: 1}},
+			}},
+		},
+		{
+			name: "Midnight without carrying days over",
+			opts: ResidencyOptions{Rule: Midnight, MaxCarryDays: -1},
+			wantDays: []ResidencyDay{
+				{Day: parseDate(t, "2024-04-04"), Countries: []string{"DE"}, Evidence: []Evidence{deEvidence(at(4, 22))}},
+				{Day: parseDate(t, "2024-04-06"), Countries: []string{"CH"}, Evidence: []Evidence{chEvidence(at(6, 12))}},
+				{Day: parseDate(t, "2024-04-07"), Evidence: []Evidence{{Time: at(7, 13), LatLng: s2.LatLngFromDegrees(30, -40)}}},
+			},
+			wantYears: []ResidencyYear{{
+				Start:     parseDate(t, "2024-01-01"),
+				End:       parseDate(t, "2024-12-31"),
+				Countries: []CountryVisit{{Country: "CH", Days: 1}, {Country: "DE", Days: 1}},
+			}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewResidencyCounter(tc.opts)
+			for _, loc := range locations {
+				if err := r.Add(loc); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if diff := cmp.Diff(tc.wantDays, r.Days(), approxLatLng); diff != "" {
+				t.Errorf("Days() diff (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantYears, r.Years()); diff != "" {
+				t.Errorf("Years() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
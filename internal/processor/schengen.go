@@ -0,0 +1,133 @@
+package processor
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// SchengenMaxDays is the number of days that may be spent in the Schengen area within any SchengenWindow.
+	SchengenMaxDays = 90
+	// SchengenWindow is the number of days of the rolling window, including the current day.
+	SchengenWindow = 180
+)
+
+// schengenCountries are the ISO 3166-1 codes of Schengen members as of 2025. Monaco, San Marino and Vatican City are
+// included as they have open borders with the Schengen area.
+var schengenCountries = map[string]bool{
+	"AT": true, "BE": true, "BG": true, "CH": true, "CZ": true, "DE": true, "DK": true, "EE": true, "ES": true,
+	"FI": true, "FR": true, "GR": true, "HR": true, "HU": true, "IS": true, "IT": true, "LI": true, "LT": true,
+	"LU": true, "LV": true, "MT": true, "NL": true, "NO": true, "PL": true, "PT": true, "RO": true, "SE": true,
+	"SI": true, "SK": true, "MC": true, "SM": true, "VA": true,
+}
+
+// nonSchengenRegions are the ISO 3166-2 codes of overseas regions of Schengen members, which are not part of the
+// Schengen area.
+var nonSchengenRegions = map[string]bool{
+	"FR-GF": true, "FR-GP": true, "FR-MQ": true, "FR-RE": true, "FR-YT": true, "NO-21": true, "NO-X01~": true,
+}
+
+// inSchengen returns whether the given day was spent in the Schengen area, at least partially. Countries are only
+// used if none of the day's regions is in that country.
+func inSchengen(d CountryDay) bool {
+	for _, r := range d.Regions {
+		country, _, _ := strings.Cut(r, "-")
+		if schengenCountries[country] && !nonSchengenRegions[r] {
+			return true
+		}
+	}
+	for _, c := range d.Countries {
+		hasRegion := slices.ContainsFunc(d.Regions, func(r string) bool { return strings.HasPrefix(r, c+"-") })
+		if schengenCountries[c] && !hasRegion {
+			return true
+		}
+	}
+	return false
+}
+
+// SchengenDays returns the days spent in the Schengen area. The days of entry and exit count as well.
+func SchengenDays(days []CountryDay) []time.Time {
+	var res []time.Time
+	for _, d := range days {
+		if inSchengen(d) {
+			res = append(res, d.Day)
+		}
+	}
+	return res
+}
+
+// SchengenDay is the state of the 90/180 allowance on a single day.
+type SchengenDay struct {
+	Day time.Time
+	// Present is whether the day was spent in the Schengen area.
+	Present bool
+	// Used is the number of days present within the window ending on this day.
+	Used int
+}
+
+// Remaining returns the number of days left in the window ending on this day, negative in case of an overstay.
+func (d SchengenDay) Remaining() int {
+	return SchengenMaxDays - d.Used
+}
+
+// SchengenAllowance computes the allowance used on every day between the first and the last of the given days spent
+// in the Schengen area, as returned by SchengenDays.
+func SchengenAllowance(present []time.Time) []SchengenDay {
+	present = slices.Clone(present)
+	slices.SortFunc(present, time.Time.Compare)
+	present = slices.CompactFunc(present, time.Time.Equal)
+	if len(present) == 0 {
+		return nil
+	}
+	var res []SchengenDay
+	// present[start:end] are the days within the window ending on day.
+	start, end := 0, 0
+	for day := present[0]; !day.After(present[len(present)-1]); day = day.AddDate(0, 0, 1) {
+		d := SchengenDay{Day: day}
+		if end < len(present) && present[end].Equal(day) {
+			d.Present = true
+			end++
+		}
+		windowStart := day.AddDate(0, 0, 1-SchengenWindow)
+		for start < end && present[start].Before(windowStart) {
+			start++
+		}
+		d.Used = end - start
+		res = append(res, d)
+	}
+	return res
+}
+
+// SchengenWarning is a period of consecutive days spent in the Schengen area with the allowance exhausted.
+type SchengenWarning struct {
+	// First and last day of the period.
+	Start time.Time
+	End   time.Time
+	// MaxUsed is the maximum number of days used within a window during the period. Values above SchengenMaxDays
+	// mean the limit was exceeded.
+	MaxUsed int
+}
+
+// Overstay returns whether the limit was exceeded during the period.
+func (w SchengenWarning) Overstay() bool {
+	return w.MaxUsed > SchengenMaxDays
+}
+
+// SchengenWarnings returns the periods where days were spent in the Schengen area at or above the limit.
+func SchengenWarnings(allowance []SchengenDay) []SchengenWarning {
+	var res []SchengenWarning
+	for i, d := range allowance {
+		if !d.Present || d.Used < SchengenMaxDays {
+			continue
+		}
+		if i > 0 && len(res) > 0 && res[len(res)-1].End.Equal(allowance[i-1].Day) {
+			w := &res[len(res)-1]
+			w.End = d.Day
+			w.MaxUsed = max(w.MaxUsed, d.Used)
+			continue
+		}
+		res = append(res, SchengenWarning{Start: d.Day, End: d.Day, MaxUsed: d.Used})
+	}
+	return res
+}
@@ -0,0 +1,76 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSchengenDays(t *testing.T) {
+	days := []CountryDay{
+		{Day: parseDate(t, "2024-01-01"), Countries: []string{"CH"}, Regions: []string{"CH-ZH"}},
+		{Day: parseDate(t, "2024-01-02"), Countries: []string{"GB"}, Regions: []string{"GB-LND"}},
+		// Guadeloupe is part of France, but not of the Schengen area.
+		{Day: parseDate(t, "2024-01-03"), Countries: []string{"FR"}, Regions: []string{"FR-GP"}},
+		// Region unknown.
+		{Day: parseDate(t, "2024-01-04"), Countries: []string{"FR"}},
+		// Flight from London to Zurich.
+		{Day: parseDate(t, "2024-01-05"), Countries: []string{"GB", "CH"}, Regions: []string{"GB-LND", "CH-ZH"}},
+	}
+	want := []time.Time{parseDate(t, "2024-01-01"), parseDate(t, "2024-01-04"), parseDate(t, "2024-01-05")}
+	if diff := cmp.Diff(want, SchengenDays(days)); diff != "" {
+		t.Errorf("SchengenDays() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestSchengenAllowance(t *testing.T) {
+	present := []time.Time{
+		parseDate(t, "2024-01-03"),
+		parseDate(t, "2024-01-01"),
+		parseDate(t, "2024-01-02"),
+		parseDate(t, "2024-01-02"),
+		parseDate(t, "2024-06-29"),
+	}
+	got := SchengenAllowance(present)
+	if len(got) != 181 {
+		t.Fatalf("SchengenAllowance() returned %d days, want 181", len(got))
+	}
+	for _, want := range []SchengenDay{
+		{Day: parseDate(t, "2024-01-01"), Present: true, Used: 1},
+		{Day: parseDate(t, "2024-01-03"), Present: true, Used: 3},
+		{Day: parseDate(t, "2024-01-04"), Used: 3},
+		// Last day of the window starting on January 1.
+		{Day: parseDate(t, "2024-06-28"), Used: 3},
+		{Day: parseDate(t, "2024-06-29"), Present: true, Used: 3},
+	} {
+		i := int(want.Day.Sub(got[0].Day).Hours() / 24)
+		if diff := cmp.Diff(want, got[i]); diff != "" {
+			t.Errorf("SchengenAllowance()[%d] diff (-want +got):\n%s", i, diff)
+		}
+	}
+	if remaining := got[180].Remaining(); remaining != 87 {
+		t.Errorf("Remaining() = %d, want 87", remaining)
+	}
+}
+
+func TestSchengenWarnings(t *testing.T) {
+	var present []time.Time
+	start := parseDate(t, "2024-01-01")
+	for i := 0; i < 91; i++ {
+		present = append(present, start.AddDate(0, 0, i))
+	}
+	// Limit is reached again, but not exceeded.
+	present = append(present, parseDate(t, "2024-06-30"))
+	want := []SchengenWarning{
+		{Start: parseDate(t, "2024-03-30"), End: parseDate(t, "2024-03-31"), MaxUsed: 91},
+		{Start: parseDate(t, "2024-06-30"), End: parseDate(t, "2024-06-30"), MaxUsed: 90},
+	}
+	got := SchengenWarnings(SchengenAllowance(present))
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("SchengenWarnings() diff (-want +got):\n%s", diff)
+	}
+	if !got[0].Overstay() || got[1].Overstay() {
+		t.Errorf("Overstay() = %t, %t, want true, false", got[0].Overstay(), got[1].Overstay())
+	}
+}
@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"log"
+	"time"
+
+	"github.com/golang/geo/earth"
+	"github.com/golang/geo/s2"
+	"github.com/google/go-units/unit"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+// StayPoint is a place where the user stayed within a radius for some time.
+type StayPoint struct {
+	// Centroid of all locations during the stay.
+	Centroid  s2.LatLng
+	Arrival   time.Time
+	Departure time.Time
+}
+
+// Dwell returns the time spent at the stay point.
+func (s StayPoint) Dwell() time.Duration {
+	return s.Departure.Sub(s.Arrival)
+}
+
+type StayPointOptions struct {
+	// Radius around the first location of a stay that all following locations of the stay are within.
+	Radius unit.Length
+	// MinDuration is the minimum dwell time for a stay to be reported.
+	MinDuration time.Duration
+}
+
+type timedLatLng struct {
+	t      time.Time
+	latlng s2.LatLng
+}
+
+// parseLocations returns the time and coordinates of all locations, dropping those with unparseable timestamps.
+func parseLocations(locations []reader.Location) []timedLatLng {
+	res := make([]timedLatLng, 0, len(locations))
+	for _, loc := range locations {
+		t, err := loc.ParsedTimestamp()
+		if err != nil {
+			log.Default().Println(err)
+			continue
+		}
+		res = append(res, timedLatLng{t: t, latlng: loc.LatLng()})
+	}
+	return res
+}
+
+// StayPoints detects stays, i.e. sequences of locations that are all within the radius of the first location
+// and span at least the minimum duration. Assumes locations are ordered by time.
+func StayPoints(locations []reader.Location, opts StayPointOptions) []StayPoint {
+	points := parseLocations(locations)
+	radius := earth.AngleFromLength(opts.Radius)
+	res := make([]StayPoint, 0)
+	for i := 0; i < len(points); {
+		j := i + 1
+		for j < len(points) && points[i].latlng.Distance(points[j].latlng) <= radius {
+			j++
+		}
+		// Locations i to j-1 are within the radius.
+		if points[j-1].t.Sub(points[i].t) < opts.MinDuration {
+			i++
+			continue
+		}
+		res = append(res, StayPoint{
+			Centroid:  centroid(points[i:j]),
+			Arrival:   points[i].t,
+			Departure: points[j-1].t,
+		})
+		i = j
+	}
+	return res
+}
+
+// centroid returns the center of the given points on the sphere.
+func centroid(points []timedLatLng) s2.LatLng {
+	var sum s2.Point
+	for _, p := range points {
+		sum = s2.Point{Vector: sum.Add(s2.PointFromLatLng(p.latlng).Vector)}
+	}
+	return s2.LatLngFromPoint(s2.Point{Vector: sum.Normalize()})
+}
@@ -0,0 +1,46 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-units/unit"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+var approxLatLng = cmp.Comparer(func(a, b s2.LatLng) bool { return a.ApproxEqual(b) })
+
+func TestStayPoints(t *testing.T) {
+	locations := []reader.Location{
+		// At home for two hours.
+		{Timestamp: "2014-04-01T07:00:00Z", LatitudeE7: 470000000, LongitudeE7: 80000000},
+		{Timestamp: "2014-04-01T08:00:00Z", LatitudeE7: 470002000, LongitudeE7: 80000000},
+		{Timestamp: "2014-04-01T09:00:00Z", LatitudeE7: 469998000, LongitudeE7: 80000000},
+		// Short stop on the way.
+		{Timestamp: "2014-04-01T09:30:00Z", LatitudeE7: 471000000, LongitudeE7: 80000000},
+		{Timestamp: "2014-04-01T09:35:00Z", LatitudeE7: 471000000, LongitudeE7: 80000000},
+		// At work for an hour.
+		{Timestamp: "2014-04-01T10:00:00Z", LatitudeE7: 472000000, LongitudeE7: 80000000},
+		{Timestamp: "2014-04-01T11:00:00Z", LatitudeE7: 472000000, LongitudeE7: 80000000},
+	}
+	want := []StayPoint{
+		{
+			Centroid:  s2.LatLngFromDegrees(47, 8),
+			Arrival:   time.Date(2014, 4, 1, 7, 0, 0, 0, time.UTC),
+			Departure: time.Date(2014, 4, 1, 9, 0, 0, 0, time.UTC),
+		}, {
+			Centroid:  s2.LatLngFromDegrees(47.2, 8),
+			Arrival:   time.Date(2014, 4, 1, 10, 0, 0, 0, time.UTC),
+			Departure: time.Date(2014, 4, 1, 11, 0, 0, 0, time.UTC),
+		},
+	}
+	got := StayPoints(locations, StayPointOptions{Radius: 100 * unit.Meter, MinDuration: 30 * time.Minute})
+	if diff := cmp.Diff(got, want, approxLatLng); diff != "" {
+		t.Errorf("StayPoints() = %v, want %v. Diff: %v", got, want, diff)
+	}
+	if d := got[0].Dwell(); d != 2*time.Hour {
+		t.Errorf("Dwell() = %v, want 2h", d)
+	}
+}
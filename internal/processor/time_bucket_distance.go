@@ -6,26 +6,41 @@ import (
 	"slices"
 	"time"
 
-	"github.com/golang/geo/earth"
 	"github.com/golang/geo/s2"
 	"github.com/google/go-units/unit"
 	"github.com/panmari/locationhistory/internal/reader"
 )
 
-func bucketTimestamp(location reader.Location, bucketDuration time.Duration) (time.Time, error) {
-	ts, err := location.ParsedTimestamp()
-	if err != nil {
-		return time.Time{}, err
+// bucketTimestamp returns the start of the bucket of the given time. Buckets are aligned on the wall clock of the
+// given time zone, e.g. daily buckets run from midnight to midnight local time.
+func bucketTimestamp(ts time.Time, bucketDuration time.Duration, tz *time.Location) time.Time {
+	l := ts.In(tz)
+	wall := time.Date(l.Year(), l.Month(), l.Day(), l.Hour(), l.Minute(), l.Second(), l.Nanosecond(), time.UTC)
+	wall = wall.Truncate(bucketDuration)
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), tz)
+}
+
+// ZoneFunc returns the time zone at the given coordinates, e.g. geo.TimeZone. Aggregators take it as option for
+// splitting days at midnight local time where each location was recorded, e.g. during a trip abroad, instead of in a
+// fixed time zone.
+type ZoneFunc func(s2.LatLng) *time.Location
+
+// zoneAt returns the time zone at the given coordinates according to at, falling back to tz if at is nil.
+func zoneAt(tz *time.Location, at ZoneFunc, ll s2.LatLng) *time.Location {
+	if at != nil {
+		return at(ll)
 	}
-	return ts.Round(bucketDuration), nil
+	return tz
 }
 
 // DistanceByTimeBucket represents a measurement aggregated to a given time-based bucket.
-// For enforcing a timezone, call Bucket.In(timeZone).Format(..)
+// Bucket is in the time zone of the anchor, so Bucket.Format(..) and Bucket.Weekday() refer to local time.
 type DistanceByTimeBucket struct {
 	// Distance to the anchor.
 	Distance unit.Length
 	Bucket   time.Time
+	// Anchor is the display name of the nearest anchor the distance was measured to, empty if the anchor has no name.
+	Anchor string
 }
 
 // MinDistance returns the minimum of two distances.
@@ -35,7 +50,7 @@ func MinDistance(a, b unit.Length) unit.Length {
 
 // MaxDistance returns the maximum of two distances.
 func MaxDistance(a, b unit.Length) unit.Length {
-	return min(a, b)
+	return max(a, b)
 }
 
 func (d DistanceByTimeBucket) String() string {
@@ -46,41 +61,80 @@ type Options struct {
 	Anchors        []Anchor
 	BucketDuration time.Duration
 	Reducer        func(a, b unit.Length) unit.Length
+	// TimeZone used for aligning buckets of anchors without time zone. Defaults to UTC.
+	TimeZone *time.Location
+	// TimeZoneAt optionally aligns buckets to the local time where each location was recorded instead of the anchor's
+	// time zone.
+	TimeZoneAt ZoneFunc
+}
+
+// Bucketer incrementally measures the distance of data points to the nearest anchor location and reduces them
+// to a single value per bucket. In contrast to TimeBucketDistance, only the buckets are kept in memory,
+// which allows consuming a stream of locations, e.g. from reader.DecodeJsonFunc.
+type Bucketer struct {
+	opts Options
+	// Buckets keyed by their UTC time, as the same instant in different time zones results in different map keys.
+	buckets map[time.Time]DistanceByTimeBucket
+}
+
+// NewBucketer creates an empty Bucketer using the given options.
+func NewBucketer(opts Options) *Bucketer {
+	if opts.TimeZone == nil {
+		opts.TimeZone = time.UTC
+	}
+	return &Bucketer{
+		opts:    opts,
+		buckets: make(map[time.Time]DistanceByTimeBucket, 365),
+	}
+}
+
+// Add reduces the given location into its bucket. It returns an error if the location's timestamp can not
+// be parsed, in which case the location is ignored. Locations without active anchor are ignored as well.
+func (b *Bucketer) Add(loc reader.Location) error {
+	t, err := loc.ParsedTimestamp()
+	if err != nil {
+		return err
+	}
+	// TODO(panmari): Consider validating that t is not before StartTime.
+	latlng := s2.LatLngFromDegrees(float64(loc.LatitudeE7)/1e7, float64(loc.LongitudeE7)/1e7)
+	anchor, dist, ok := nearestAnchor(b.opts.Anchors, latlng, t)
+	if !ok {
+		return nil
+	}
+	tz := zoneAt(anchor.zone(b.opts.TimeZone), b.opts.TimeZoneAt, latlng)
+	ts := bucketTimestamp(t, b.opts.BucketDuration, tz)
+	key := ts.UTC()
+	d, ok := b.buckets[key]
+	if !ok {
+		b.buckets[key] = DistanceByTimeBucket{Distance: dist, Bucket: ts, Anchor: anchor.DisplayName()}
+		return nil
+	}
+	if reduced := b.opts.Reducer(d.Distance, dist); reduced != d.Distance {
+		b.buckets[key] = DistanceByTimeBucket{Distance: reduced, Bucket: ts, Anchor: anchor.DisplayName()}
+	}
+	return nil
+}
+
+// Buckets returns all buckets seen so far, ordered by time.
+func (b *Bucketer) Buckets() []DistanceByTimeBucket {
+	res := make([]DistanceByTimeBucket, 0, len(b.buckets))
+	for _, d := range b.buckets {
+		res = append(res, d)
+	}
+	slices.SortFunc(res, func(a, b DistanceByTimeBucket) int {
+		return a.Bucket.Compare(b.Bucket)
+	})
+	return res
 }
 
 // TimeBucketDistance measures the distance of each data point to the anchor location for each duration and reduces
 // it to a single value using the given reducer fuction.
 func TimeBucketDistance(locations []reader.Location, opts Options) ([]DistanceByTimeBucket, error) {
-	minDistanceByDate := make(map[time.Time]unit.Length, 365)
-
+	b := NewBucketer(opts)
 	for _, loc := range locations {
-		ts, err := bucketTimestamp(loc, opts.BucketDuration)
-		if err != nil {
+		if err := b.Add(loc); err != nil {
 			log.Default().Println(err)
-			continue
-		}
-		// TODO(panmari): Consider validating that ts is not before StartTime.
-		for len(opts.Anchors) > 1 && ts.After(opts.Anchors[1].StartTime) {
-			opts.Anchors = opts.Anchors[1:]
 		}
-		latlng := s2.LatLngFromDegrees(float64(loc.LatitudeE7)/1e7, float64(loc.LongitudeE7)/1e7)
-		dist := earth.LengthFromAngle(latlng.Distance(opts.Anchors[0].Location))
-		d, ok := minDistanceByDate[ts]
-		if !ok {
-			minDistanceByDate[ts] = dist
-			continue
-		}
-		minDistanceByDate[ts] = opts.Reducer(d, dist)
-	}
-	res := make([]DistanceByTimeBucket, 0, len(minDistanceByDate))
-	for date, distance := range minDistanceByDate {
-		res = append(res, DistanceByTimeBucket{
-			Distance: distance,
-			Bucket:   date,
-		})
 	}
-	slices.SortFunc(res, func(a, b DistanceByTimeBucket) int {
-		return a.Bucket.Compare(b.Bucket)
-	})
-	return res, nil
+	return b.Buckets(), nil
 }
@@ -46,7 +46,7 @@ func TestTimeBucketDistanceDaily(t *testing.T) {
 				StartTime: time.Time{},
 				Location:  s2.LatLngFromDegrees(46.9287872, 7.4171385),
 			}},
-			want: []DistanceByTimeBucket{{0 * unit.Kilometer, parseDate(t, "2014-04-01")}},
+			want: []DistanceByTimeBucket{{Distance: 0 * unit.Kilometer, Bucket: parseDate(t, "2014-04-01")}},
 		},
 		{
 			name: "Anchor far away gives non-zero",
@@ -54,7 +54,7 @@ func TestTimeBucketDistanceDaily(t *testing.T) {
 				StartTime: time.Time{},
 				Location:  s2.LatLngFromDegrees(50, 5),
 			}},
-			want: []DistanceByTimeBucket{{385.159008 * unit.Kilometer, parseDate(t, "2014-04-01")}},
+			want: []DistanceByTimeBucket{{Distance: 385.159008 * unit.Kilometer, Bucket: parseDate(t, "2014-04-01")}},
 		},
 		{
 			name: "Timezone",
@@ -62,7 +62,7 @@ func TestTimeBucketDistanceDaily(t *testing.T) {
 				StartTime: time.Time{},
 				Location:  s2.LatLngFromDegrees(50, 5),
 			}},
-			want: []DistanceByTimeBucket{{385.159008 * unit.Kilometer, parseDate(t, "2014-04-01")}},
+			want: []DistanceByTimeBucket{{Distance: 385.159008 * unit.Kilometer, Bucket: parseDate(t, "2014-04-01")}},
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
@@ -101,8 +101,8 @@ func TestTimeBucketDistanceHourly(t *testing.T) {
 				Location:  s2.LatLngFromDegrees(50, 5),
 			}},
 			want: []DistanceByTimeBucket{
-				{385.159008 * unit.Kilometer, time.Date(2014, 04, 01, 8, 0, 0, 0, time.UTC)},
-				{385.159008 * unit.Kilometer, time.Date(2014, 04, 01, 11, 0, 0, 0, time.UTC)},
+				{Distance: 385.159008 * unit.Kilometer, Bucket: time.Date(2014, 04, 01, 7, 0, 0, 0, time.UTC)},
+				{Distance: 385.159008 * unit.Kilometer, Bucket: time.Date(2014, 04, 01, 10, 0, 0, 0, time.UTC)},
 			},
 		},
 	} {
@@ -147,7 +147,7 @@ func TestDailyDistanceMultipleDays(t *testing.T) {
 				Location:  s2.LatLngFromDegrees(46.9287872, 7.4171385),
 			}},
 			bucketDuration: time.Hour * 24,
-			want:           []DistanceByTimeBucket{{0 * unit.Kilometer, parseDate(t, "2014-04-01")}, {111.2615837 * unit.Kilometer, parseDate(t, "2014-05-03")}},
+			want:           []DistanceByTimeBucket{{Distance: 0 * unit.Kilometer, Bucket: parseDate(t, "2014-04-01")}, {Distance: 111.2615837 * unit.Kilometer, Bucket: parseDate(t, "2014-05-02")}},
 		},
 		{
 			name: "Anchor at one location gives zero with hourly bucket",
@@ -157,9 +157,9 @@ func TestDailyDistanceMultipleDays(t *testing.T) {
 			}},
 			bucketDuration: time.Hour,
 			want: []DistanceByTimeBucket{
-				{0 * unit.Kilometer, time.Date(2014, 4, 1, 8, 0, 0, 0, time.UTC)},
-				{0 * unit.Kilometer, time.Date(2014, 4, 1, 9, 0, 0, 0, time.UTC)},
-				{111.2615837 * unit.Kilometer, time.Date(2014, 5, 2, 16, 0, 0, 0, time.UTC)},
+				{Distance: 0 * unit.Kilometer, Bucket: time.Date(2014, 4, 1, 7, 0, 0, 0, time.UTC)},
+				{Distance: 0 * unit.Kilometer, Bucket: time.Date(2014, 4, 1, 9, 0, 0, 0, time.UTC)},
+				{Distance: 111.2615837 * unit.Kilometer, Bucket: time.Date(2014, 5, 2, 15, 0, 0, 0, time.UTC)},
 			},
 		},
 		{
@@ -176,7 +176,23 @@ func TestDailyDistanceMultipleDays(t *testing.T) {
 				Location:  s2.LatLngFromDegrees(45.9281883, 7.4156002),
 			}},
 			bucketDuration: time.Hour * 24,
-			want:           []DistanceByTimeBucket{{0 * unit.Kilometer, parseDate(t, "2014-04-01")}, {0 * unit.Kilometer, parseDate(t, "2014-05-03")}},
+			want:           []DistanceByTimeBucket{{Distance: 0 * unit.Kilometer, Bucket: parseDate(t, "2014-04-01")}, {Distance: 0 * unit.Kilometer, Bucket: parseDate(t, "2014-05-02")}},
+		},
+		{
+			name: "Simultaneous anchors use nearest",
+			anchor: []Anchor{{
+				Name:     "home",
+				Location: s2.LatLngFromDegrees(46.9287872, 7.4171385),
+			}, {
+				Name:     "office",
+				Label:    "Office in Sion",
+				Location: s2.LatLngFromDegrees(46.0281883, 7.4156002),
+			}},
+			bucketDuration: time.Hour * 24,
+			want: []DistanceByTimeBucket{
+				{Distance: 0 * unit.Kilometer, Bucket: parseDate(t, "2014-04-01"), Anchor: "home"},
+				{Distance: 11.1194926 * unit.Kilometer, Bucket: parseDate(t, "2014-05-02"), Anchor: "Office in Sion"},
+			},
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
@@ -188,3 +204,190 @@ func TestDailyDistanceMultipleDays(t *testing.T) {
 		})
 	}
 }
+
+func TestBucketerMatchesTimeBucketDistance(t *testing.T) {
+	locations := []reader.Location{
+		{
+			Timestamp:   "2014-04-01T07:55:51.093Z",
+			LatitudeE7:  469287872,
+			LongitudeE7: 74171385,
+		},
+		{
+			Timestamp: "invalid",
+		},
+		{
+			Timestamp:   "2014-05-02T15:55:51.093Z",
+			LatitudeE7:  459281883,
+			LongitudeE7: 74156002,
+		},
+	}
+	opts := Options{
+		Anchors:        []Anchor{{Location: s2.LatLngFromDegrees(46.9287872, 7.4171385)}},
+		BucketDuration: time.Hour * 24,
+		Reducer:        MinDistance,
+	}
+	want, err := TimeBucketDistance(locations, opts)
+	if err != nil {
+		t.Fatalf("TimeBucketDistance() = %v", err)
+	}
+	b := NewBucketer(opts)
+	errCount := 0
+	for _, loc := range locations {
+		if err := b.Add(loc); err != nil {
+			errCount++
+		}
+	}
+	got := b.Buckets()
+	if errCount != 1 || !cmp.Equal(got, want, toKilometers, cmpopts.EquateApprox(0.001, 0.001)) {
+		t.Errorf("Bucketer.Buckets() = %v with %d errors, want %v with 1 error", got, errCount, want)
+	}
+}
+
+func TestBucketTimestamp(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name           string
+		ts             time.Time
+		bucketDuration time.Duration
+		tz             *time.Location
+		want           time.Time
+	}{
+		{
+			name:           "Daily in UTC",
+			ts:             time.Date(2014, 4, 1, 7, 55, 0, 0, time.UTC),
+			bucketDuration: time.Hour * 24,
+			tz:             time.UTC,
+			want:           time.Date(2014, 4, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:           "Daily in UTC keeps evening on the same day",
+			ts:             time.Date(2014, 4, 1, 23, 59, 0, 0, time.UTC),
+			bucketDuration: time.Hour * 24,
+			tz:             time.UTC,
+			want:           time.Date(2014, 4, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:           "Daily aligned to local midnight",
+			ts:             time.Date(2014, 4, 1, 18, 0, 0, 0, time.UTC),
+			bucketDuration: time.Hour * 24,
+			tz:             la,
+			want:           time.Date(2014, 4, 1, 0, 0, 0, 0, la),
+		},
+		{
+			name:           "Hourly with half hour offset",
+			ts:             time.Date(2014, 4, 1, 8, 25, 0, 0, time.UTC),
+			bucketDuration: time.Hour,
+			tz:             kolkata,
+			want:           time.Date(2014, 4, 1, 13, 0, 0, 0, kolkata),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := bucketTimestamp(tc.ts, tc.bucketDuration, tc.tz)
+			if !got.Equal(tc.want) || got.Location() != tc.tz {
+				t.Errorf("bucketTimestamp(%v) = %v, want %v", tc.ts, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBucketerUsesAnchorTimeZone(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zurich := s2.LatLngFromDegrees(47.37, 8.54)
+	sf := s2.LatLngFromDegrees(37.77, -122.42)
+	b := NewBucketer(Options{
+		Anchors: []Anchor{
+			{Name: "zurich", Location: zurich},
+			{Name: "zurich", StartTime: time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC), Location: sf, TimeZone: la},
+		},
+		BucketDuration: time.Hour * 24,
+		Reducer:        MinDistance,
+		TimeZone:       time.UTC,
+	})
+	for _, loc := range []reader.Location{
+		{Timestamp: "2016-01-10T18:00:00Z", LatitudeE7: 473700000, LongitudeE7: 85400000},
+		{Timestamp: "2016-03-10T18:00:00Z", LatitudeE7: 377700000, LongitudeE7: -1224200000},
+	} {
+		if err := b.Add(loc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []time.Time{
+		time.Date(2016, 1, 10, 0, 0, 0, 0, time.UTC),
+		time.Date(2016, 3, 10, 0, 0, 0, 0, la),
+	}
+	var got []time.Time
+	for _, d := range b.Buckets() {
+		got = append(got, d.Bucket)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Bucketer.Buckets() diff (-want +got):\n%s", diff)
+	}
+	if zone, _ := got[1].Zone(); zone != "PST" {
+		t.Errorf("Bucketer.Buckets()[1] zone = %s, want PST", zone)
+	}
+}
+
+func TestBucketerUsesTimeZoneAt(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := NewBucketer(Options{
+		Anchors:        []Anchor{{Name: "zurich", Location: s2.LatLngFromDegrees(47.37, 8.54)}},
+		BucketDuration: time.Hour,
+		Reducer:        MinDistance,
+		TimeZoneAt: func(s2.LatLng) *time.Location {
+			return tokyo
+		},
+	})
+	if err := b.Add(reader.Location{Timestamp: "2016-01-10T17:10:00Z", LatitudeE7: 356762000, LongitudeE7: 1396503000}); err != nil {
+		t.Fatal(err)
+	}
+	got := b.Buckets()
+	if len(got) != 1 || got[0].Bucket.Hour() != 2 || got[0].Bucket.Location() != tokyo {
+		t.Errorf("Bucketer.Buckets() = %v, want a single bucket at 02:00 in Tokyo", got)
+	}
+}
+
+func TestBucketerReducers(t *testing.T) {
+	locations := []reader.Location{
+		{Timestamp: "2016-01-10T08:00:00Z", LatitudeE7: 470000000, LongitudeE7: 80000000},
+		{Timestamp: "2016-01-10T12:00:00Z", LatitudeE7: 480000000, LongitudeE7: 80000000},
+		{Timestamp: "2016-01-10T18:00:00Z", LatitudeE7: 471000000, LongitudeE7: 80000000},
+	}
+	for _, tc := range []struct {
+		name    string
+		reducer func(a, b unit.Length) unit.Length
+		want    unit.Length
+	}{
+		{name: "MinDistance", reducer: MinDistance, want: 0},
+		{name: "MaxDistance", reducer: MaxDistance, want: 111.195 * unit.Kilometer},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewBucketer(Options{
+				Anchors:        []Anchor{{Location: s2.LatLngFromDegrees(47, 8)}},
+				BucketDuration: time.Hour * 24,
+				Reducer:        tc.reducer,
+			})
+			for _, loc := range locations {
+				if err := b.Add(loc); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want := []DistanceByTimeBucket{{Distance: tc.want, Bucket: parseDate(t, "2016-01-10")}}
+			if diff := cmp.Diff(want, b.Buckets(), toKilometers, cmpopts.EquateApprox(0, 0.001)); diff != "" {
+				t.Errorf("Bucketer.Buckets() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package processor
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/google/go-units/unit"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+// Mode is a mode of transport.
+type Mode string
+
+const (
+	Walking Mode = "walking"
+	Cycling Mode = "cycling"
+	Driving Mode = "driving"
+	Train   Mode = "train"
+	Flight  Mode = "flight"
+)
+
+// Modes lists all modes of transport, ordered by their typical speed.
+var Modes = []Mode{Walking, Cycling, Driving, Train, Flight}
+
+// maxSpeed is the highest plausible speed of every mode, used for classifying segments without activity and
+// for rejecting recognized activities that are too slow for the speed of a segment.
+var maxSpeed = map[Mode]unit.Speed{
+	Walking: 7 * unit.KilometerPerHour,
+	Cycling: 25 * unit.KilometerPerHour,
+	Driving: 150 * unit.KilometerPerHour,
+	Train:   320 * unit.KilometerPerHour,
+}
+
+// minFlightJump, maxFlightJump and minFlightJumpSpeed bound the segments without fixes in between that count as
+// flights even though they are slower than driving, e.g. because the phone was in flight mode from home to the hotel.
+// Door to door, including the time spent at airports, such jumps average about 100km/h. Slower jumps are more likely
+// drives with a night in between, and longer gaps are too uncertain to count as a single flight.
+const (
+	minFlightJump      = 300 * unit.Kilometer
+	maxFlightJump      = 24 * time.Hour
+	minFlightJumpSpeed = 80 * unit.KilometerPerHour
+)
+
+// segmentSpeed returns the average speed of a segment of the given length traveled in d, which must be positive.
+func segmentSpeed(length unit.Length, d time.Duration) unit.Speed {
+	return unit.Speed(length.Meters()/d.Seconds()) * unit.MeterPerSecond
+}
+
+// isFlight returns true if a segment of the given length traveled in d is a flight, i.e. either a long jump at a
+// plausible speed or too fast for a train.
+func isFlight(length unit.Length, d time.Duration) bool {
+	speed := segmentSpeed(length, d)
+	if length >= minFlightJump && d <= maxFlightJump && speed >= minFlightJumpSpeed {
+		return true
+	}
+	return speed > maxSpeed[Train]
+}
+
+// activityModes maps activity types of raw records and semantic segments to modes of transport.
+var activityModes = map[string]Mode{
+	"ON_FOOT":                 Walking,
+	"WALKING":                 Walking,
+	"RUNNING":                 Walking,
+	"ON_BICYCLE":              Cycling,
+	"CYCLING":                 Cycling,
+	"IN_VEHICLE":              Driving,
+	"IN_ROAD_VEHICLE":         Driving,
+	"IN_FOUR_WHEELER_VEHICLE": Driving,
+	"IN_TWO_WHEELER_VEHICLE":  Driving,
+	"IN_CAR":                  Driving,
+	"IN_BUS":                  Driving,
+	"IN_PASSENGER_VEHICLE":    Driving,
+	"MOTORCYCLING":            Driving,
+	"IN_RAIL_VEHICLE":         Train,
+	"IN_TRAIN":                Train,
+	"IN_SUBWAY":               Train,
+	"IN_TRAM":                 Train,
+	"FLYING":                  Flight,
+}
+
+// classify returns the mode of transport of a segment traveled at the given speed. The recognized activity is used
+// unless the speed is implausible for it, otherwise the mode is inferred from the speed alone.
+func classify(speed unit.Speed, activity reader.Activity, hasActivity bool) Mode {
+	if mode, ok := activityModes[activity.Type]; hasActivity && ok {
+		if limit, ok := maxSpeed[mode]; !ok || speed <= limit {
+			return mode
+		}
+		if mode == Driving {
+			// Activity recognition does not distinguish trains from cars well.
+			return classify(speed, reader.Activity{}, false)
+		}
+	}
+	for _, mode := range Modes {
+		if limit, ok := maxSpeed[mode]; !ok || speed <= limit {
+			return mode
+		}
+	}
+	return Flight
+}
+
+// ModeByTimeBucket is the distance and time traveled with a mode of transport within a time bucket.
+type ModeByTimeBucket struct {
+	Mode     Mode
+	Distance unit.Length
+	Duration time.Duration
+	Bucket   time.Time
+}
+
+type ModeOptions struct {
+	// MinSegment is the minimum length of a segment to count, in addition to the accuracy of its fixes.
+	MinSegment unit.Length
+	// MaxGap is the maximum time between fixes of a segment. Longer segments, e.g. because the phone was turned off,
+	// only count if they are too fast for driving, or if they are flights, i.e. jumps of at least 300km within a day
+	// at 80km/h or more.
+	// Defaults to an hour.
+	MaxGap time.Duration
+	// TimeZone used for splitting days. Defaults to UTC.
+	TimeZone *time.Location
+	// TimeZoneAt optionally overrides TimeZone with the local time where each location was recorded.
+	TimeZoneAt ZoneFunc
+}
+
+// ModeBucketer classifies the segments between consecutive fixes by mode of transport, based on their speed and the
+// activities recognized with the fixes, and sums their distance and time per day. Movements within the accuracy of
+// the fixes are skipped like for PathLengthBucketer.
+// Locations are expected to be added in ascending order of time.
+type ModeBucketer struct {
+	opts ModeOptions
+	// Buckets keyed by their UTC time and mode.
+	buckets  map[time.Time]map[Mode]*ModeByTimeBucket
+	smoother smoother
+	// activity is the last recognized activity, as recognitions are only attached to some locations. It is used for
+	// segments ending within MaxGap of activityTime.
+	activity     reader.Activity
+	activityTime time.Time
+}
+
+// NewModeBucketer creates an empty ModeBucketer using the given options.
+func NewModeBucketer(opts ModeOptions) *ModeBucketer {
+	if opts.MaxGap == 0 {
+		opts.MaxGap = time.Hour
+	}
+	if opts.TimeZone == nil {
+		opts.TimeZone = time.UTC
+	}
+	return &ModeBucketer{
+		opts:     opts,
+		buckets:  make(map[time.Time]map[Mode]*ModeByTimeBucket, 365),
+		smoother: smoother{minSegment: opts.MinSegment},
+	}
+}
+
+// Add classifies the segment from the previous location to the given one and adds it to the day of the given
+// location. It returns an error if the location's timestamp can not be parsed or is older than the previous one, in
+// which case the location is ignored.
+func (m *ModeBucketer) Add(loc reader.Location) error {
+	t, err := loc.ParsedTimestamp()
+	if err != nil {
+		return err
+	}
+	if err := checkOrder(t, m.smoother.latest); err != nil {
+		return err
+	}
+	if activity, ok := loc.DominantActivity(); ok {
+		m.activity, m.activityTime = activity, t
+	}
+	from, length, ok := m.smoother.next(newFix(t, loc))
+	if !ok {
+		return nil
+	}
+	d := t.Sub(from.t)
+	if d == 0 {
+		// Without time, there is no speed for classifying the segment.
+		return nil
+	}
+	speed := segmentSpeed(length, d)
+	flight := isFlight(length, d)
+	if !flight && d > m.opts.MaxGap && speed <= maxSpeed[Driving] {
+		// The mode of transport is unknown, the phone may have been turned off for days.
+		return nil
+	}
+	latlng := loc.LatLng()
+	tz := zoneAt(m.opts.TimeZone, m.opts.TimeZoneAt, latlng)
+	day := localDate(t, tz)
+	hasActivity := !m.activityTime.IsZero() && t.Sub(m.activityTime) <= m.opts.MaxGap
+	mode := classify(speed, m.activity, hasActivity)
+	if flight {
+		mode = Flight
+	}
+	if m.buckets[day.UTC()] == nil {
+		m.buckets[day.UTC()] = make(map[Mode]*ModeByTimeBucket)
+	}
+	b := m.buckets[day.UTC()][mode]
+	if b == nil {
+		b = &ModeByTimeBucket{Mode: mode, Bucket: day}
+		m.buckets[day.UTC()][mode] = b
+	}
+	b.Distance += length
+	b.Duration += d
+	return nil
+}
+
+// Buckets returns the distance and time per mode for every day seen so far, ordered by day, then by mode.
+func (m *ModeBucketer) Buckets() []ModeByTimeBucket {
+	var res []ModeByTimeBucket
+	for _, byMode := range m.buckets {
+		for _, b := range byMode {
+			res = append(res, *b)
+		}
+	}
+	slices.SortFunc(res, func(a, b ModeByTimeBucket) int {
+		return cmp.Or(a.Bucket.Compare(b.Bucket), cmp.Compare(slices.Index(Modes, a.Mode), slices.Index(Modes, b.Mode)))
+	})
+	return res
+}
+
+// sumModes sums the buckets with the same start according to the given function per mode. Starts are compared by
+// date, so days in different time zones are summed as well. Assumes buckets are ordered by time.
+func sumModes(items []ModeByTimeBucket, start func(time.Time) time.Time) []ModeByTimeBucket {
+	type key struct {
+		period string
+		mode   Mode
+	}
+	sums := make(map[key]*ModeByTimeBucket)
+	var periods []time.Time
+	for _, m := range items {
+		s := start(m.Bucket)
+		if len(periods) == 0 || periods[len(periods)-1].Format(time.DateOnly) != s.Format(time.DateOnly) {
+			periods = append(periods, s)
+		}
+		k := key{period: s.Format(time.DateOnly), mode: m.Mode}
+		if sums[k] == nil {
+			sums[k] = &ModeByTimeBucket{Mode: m.Mode, Bucket: s}
+		}
+		sums[k].Distance += m.Distance
+		sums[k].Duration += m.Duration
+	}
+	var res []ModeByTimeBucket
+	for _, p := range periods {
+		for _, mode := range Modes {
+			if b := sums[key{period: p.Format(time.DateOnly), mode: mode}]; b != nil {
+				res = append(res, *b)
+			}
+		}
+	}
+	return res
+}
+
+// SumModesMonthly sums daily buckets per mode to calendar months.
+func SumModesMonthly(items []ModeByTimeBucket) []ModeByTimeBucket {
+	return sumModes(items, func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	})
+}
+
+// SumModesYearly sums daily buckets per mode to calendar years.
+func SumModesYearly(items []ModeByTimeBucket) []ModeByTimeBucket {
+	return sumModes(items, func(t time.Time) time.Time {
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+	})
+}
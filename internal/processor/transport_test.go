@@ -0,0 +1,223 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-units/unit"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+func TestClassify(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		speed    float64
+		activity string
+		want     Mode
+	}{
+		{name: "Walking by speed", speed: 5, want: Walking},
+		{name: "Cycling by speed", speed: 20, want: Cycling},
+		{name: "Driving by speed", speed: 100, want: Driving},
+		{name: "Train by speed", speed: 250, want: Train},
+		{name: "Flight by speed", speed: 800, want: Flight},
+		{name: "Slow cycling", speed: 5, activity: "ON_BICYCLE", want: Cycling},
+		{name: "Driving in traffic", speed: 15, activity: "IN_VEHICLE", want: Driving},
+		{name: "Slow train", speed: 60, activity: "IN_RAIL_VEHICLE", want: Train},
+		{name: "High speed train recognized as vehicle", speed: 250, activity: "IN_VEHICLE", want: Train},
+		{name: "Implausible walking", speed: 60, activity: "WALKING", want: Driving},
+		{name: "Still is ignored", speed: 20, activity: "STILL", want: Cycling},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			speed := unit.Speed(tc.speed) * unit.KilometerPerHour
+			got := classify(speed, reader.Activity{Type: tc.activity, Confidence: 80}, tc.activity != "")
+			if got != tc.want {
+				t.Errorf("classify(%v, %q) = %v, want %v", tc.speed, tc.activity, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestModeBucketer(t *testing.T) {
+	withAccuracy := func(loc reader.Location, accuracy int) reader.Location {
+		loc.Accuracy = accuracy
+		return loc
+	}
+	with := func(loc reader.Location, activity string) reader.Location {
+		loc.Accuracy = 10
+		if activity != "" {
+			loc.Activity = []reader.Activities{activities(loc.Timestamp, reader.Activity{Type: activity, Confidence: 80})}
+		}
+		return loc
+	}
+	at := func(month, day, hour, minute int) time.Time {
+		return time.Date(2024, time.Month(month), day, hour, minute, 0, 0, time.UTC)
+	}
+	locations := []reader.Location{
+		with(location(at(4, 1, 8, 0), 47, 8), "WALKING"),
+		with(location(at(4, 1, 8, 10), 47.01, 8), ""),
+		// Too fast for walking.
+		with(location(at(4, 1, 8, 20), 47.1, 8), ""),
+		// Standing still with better accuracy, so the next segment starts here.
+		withAccuracy(location(at(4, 1, 9, 0), 47.1, 8), 5),
+		with(location(at(4, 1, 10, 0), 48.1, 8), "IN_RAIL_VEHICLE"),
+		// Phone turned off during a flight to London.
+		with(location(at(4, 1, 12, 0), 51.5, -0.1), ""),
+		// A month later, back home. The mode of the segment from London is unknown.
+		with(location(at(5, 2, 8, 0), 47, 8), "WALKING"),
+		with(location(at(5, 2, 8, 10), 47.01, 8), ""),
+	}
+	m := NewModeBucketer(ModeOptions{})
+	for _, loc := range locations {
+		if err := m.Add(loc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	daily := m.Buckets()
+	april, may := parseDate(t, "2024-04-01"), parseDate(t, "2024-05-02")
+	for _, tc := range []struct {
+		name string
+		got  []ModeByTimeBucket
+		want []ModeByTimeBucket
+	}{
+		{
+			name: "Daily",
+			got:  daily,
+			want: []ModeByTimeBucket{
+				{Mode: Walking, Distance: 1.112 * unit.Kilometer, Duration: 10 * time.Minute, Bucket: april},
+				{Mode: Driving, Distance: 10.008 * unit.Kilometer, Duration: 10 * time.Minute, Bucket: april},
+				{Mode: Train, Distance: 111.195 * unit.Kilometer, Duration: time.Hour, Bucket: april},
+				{Mode: Flight, Distance: 692.861 * unit.Kilometer, Duration: 2 * time.Hour, Bucket: april},
+				{Mode: Walking, Distance: 1.112 * unit.Kilometer, Duration: 10 * time.Minute, Bucket: may},
+			},
+		},
+		{
+			name: "Monthly",
+			got:  SumModesMonthly(daily),
+			want: []ModeByTimeBucket{
+				{Mode: Walking, Distance: 1.112 * unit.Kilometer, Duration: 10 * time.Minute, Bucket: april},
+				{Mode: Driving, Distance: 10.008 * unit.Kilometer, Duration: 10 * time.Minute, Bucket: april},
+				{Mode: Train, Distance: 111.195 * unit.Kilometer, Duration: time.Hour, Bucket: april},
+				{Mode: Flight, Distance: 692.861 * unit.Kilometer, Duration: 2 * time.Hour, Bucket: april},
+				{Mode: Walking, Distance: 1.112 * unit.Kilometer, Duration: 10 * time.Minute, Bucket: parseDate(t, "2024-05-01")},
+			},
+		},
+		{
+			name: "Yearly",
+			got:  SumModesYearly(daily),
+			want: []ModeByTimeBucket{
+				{Mode: Walking, Distance: 2.224 * unit.Kilometer, Duration: 20 * time.Minute, Bucket: parseDate(t, "2024-01-01")},
+				{Mode: Driving, Distance: 10.008 * unit.Kilometer, Duration: 10 * time.Minute, Bucket: parseDate(t, "2024-01-01")},
+				{Mode: Train, Distance: 111.195 * unit.Kilometer, Duration: time.Hour, Bucket: parseDate(t, "2024-01-01")},
+				{Mode: Flight, Distance: 692.861 * unit.Kilometer, Duration: 2 * time.Hour, Bucket: parseDate(t, "2024-01-01")},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.got, toKilometers, cmpopts.EquateApprox(0, 0.001)); diff != "" {
+				t.Errorf("diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestModeBucketerFlightJump(t *testing.T) {
+	departure := time.Date(2024, 4, 1, 6, 0, 0, 0, time.UTC)
+	zurich, london, geneva := s2.LatLngFromDegrees(47.45, 8.56), s2.LatLngFromDegrees(51.47, -0.45), s2.LatLngFromDegrees(46.24, 6.11)
+	for _, tc := range []struct {
+		name     string
+		to       s2.LatLng
+		duration time.Duration
+		activity string
+		want     []ModeByTimeBucket
+	}{
+		{
+			name:     "Faster than driving",
+			to:       london,
+			duration: 5 * time.Hour,
+			want:     []ModeByTimeBucket{{Mode: Flight, Distance: 788.969 * unit.Kilometer, Duration: 5 * time.Hour, Bucket: parseDate(t, "2024-04-01")}},
+		},
+		{
+			// Phone turned off at home and turned on again at the hotel, at about 100km/h on average.
+			name:     "Door to door",
+			to:       london,
+			duration: 8 * time.Hour,
+			want:     []ModeByTimeBucket{{Mode: Flight, Distance: 788.969 * unit.Kilometer, Duration: 8 * time.Hour, Bucket: parseDate(t, "2024-04-01")}},
+		},
+		{
+			// No train keeps 305km/h on average between two fixes, even though the phone claims to be in one.
+			name:     "Within an hour despite train activity",
+			to:       s2.LatLngFromDegrees(47.45, 4.5),
+			duration: time.Hour,
+			activity: "IN_RAIL_VEHICLE",
+			want:     []ModeByTimeBucket{{Mode: Flight, Distance: 305.252 * unit.Kilometer, Duration: time.Hour, Bucket: parseDate(t, "2024-04-01")}},
+		},
+		{
+			// Phone turned off while driving to Paris with a night in between, at about 25km/h on average.
+			name:     "Overnight drive",
+			to:       s2.LatLngFromDegrees(48.72, 2.38),
+			duration: 20 * time.Hour,
+		},
+		{
+			name:     "More than a day is not a single flight",
+			to:       london,
+			duration: 30 * time.Hour,
+		},
+		{
+			name:     "Too short for a jump",
+			to:       geneva,
+			duration: 5 * time.Hour,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			arrival := location(departure.Add(tc.duration), tc.to.Lat.Degrees(), tc.to.Lng.Degrees())
+			if tc.activity != "" {
+				arrival.Activity = []reader.Activities{activities(arrival.Timestamp, reader.Activity{Type: tc.activity, Confidence: 80})}
+			}
+			m := NewModeBucketer(ModeOptions{})
+			for _, loc := range []reader.Location{location(departure, zurich.Lat.Degrees(), zurich.Lng.Degrees()), arrival} {
+				if err := m.Add(loc); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if diff := cmp.Diff(tc.want, m.Buckets(), toKilometers, cmpopts.EquateApprox(0, 0.001)); diff != "" {
+				t.Errorf("Buckets() diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestModeBucketerSkipsLocationsOutOfOrder(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 4, 1, hour, minute, 0, 0, time.UTC)
+	}
+	inOrder := []reader.Location{
+		location(at(8, 0), 47, 8),
+		location(at(8, 10), 47.01, 8),
+		location(at(8, 20), 47.02, 8),
+	}
+	want := NewModeBucketer(ModeOptions{})
+	for _, loc := range inOrder {
+		if err := want.Add(loc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := NewModeBucketer(ModeOptions{})
+	for _, loc := range []reader.Location{
+		inOrder[0],
+		inOrder[1],
+		// E.g. a raw signal of Timeline.json passed on after the semantic segments.
+		location(at(7, 0), 47.5, 8),
+		inOrder[2],
+	} {
+		err := got.Add(loc)
+		if wantErr := loc.Timestamp == at(7, 0).Format(time.RFC3339); (err != nil) != wantErr {
+			t.Errorf("Add(%v) = %v, want error: %t", loc.Timestamp, err, wantErr)
+		}
+	}
+	if diff := cmp.Diff(want.Buckets(), got.Buckets()); diff != "" {
+		t.Errorf("Buckets() diff (-want +got):\n%s", diff)
+	}
+}
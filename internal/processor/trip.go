@@ -0,0 +1,136 @@
+package processor
+
+import (
+	"slices"
+	"time"
+
+	"github.com/golang/geo/earth"
+	"github.com/golang/geo/s2"
+	"github.com/google/go-units/unit"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+// Trip is a period of consecutive days spent away from all anchors.
+type Trip struct {
+	// First and last day of the trip.
+	Start time.Time
+	End   time.Time
+	// MaxDistance is the distance of the farthest point from the nearest anchor.
+	MaxDistance unit.Length
+	Farthest    s2.LatLng
+	// PathLength is the sum of distances between consecutive locations during the trip.
+	PathLength unit.Length
+}
+
+// Days returns the number of days of the trip.
+func (t Trip) Days() int {
+	return int(t.End.Sub(t.Start).Hours()/24) + 1
+}
+
+type TripOptions struct {
+	Anchors []Anchor
+	// MinDistance from the nearest anchor for a day to count as away.
+	MinDistance unit.Length
+	// TimeZone used for determining days, unless the nearest anchor has its own time zone.
+	TimeZone *time.Location
+}
+
+// tripDay aggregates the locations of a single day.
+type tripDay struct {
+	maxDistance unit.Length
+	farthest    s2.LatLng
+	pathLength  unit.Length
+}
+
+// TripDetector detects trips from a stream of locations. A day is away if any location is further than MinDistance
+// from the active anchor, and consecutive away days form a trip. Days without locations in between away days
+// do not interrupt a trip, e.g. on long flights.
+// Locations are expected to be added in ascending order of time for computing path lengths.
+type TripDetector struct {
+	opts TripOptions
+	days map[time.Time]*tripDay
+	prev *s2.LatLng
+}
+
+// NewTripDetector creates an empty TripDetector using the given options.
+func NewTripDetector(opts TripOptions) *TripDetector {
+	if opts.TimeZone == nil {
+		opts.TimeZone = time.UTC
+	}
+	return &TripDetector{
+		opts: opts,
+		days: make(map[time.Time]*tripDay, 365),
+	}
+}
+
+// Add measures the given location. It returns an error if the location's timestamp can not be parsed,
+// in which case the location is ignored. Locations without active anchor are ignored as well.
+func (d *TripDetector) Add(loc reader.Location) error {
+	t, err := loc.ParsedTimestamp()
+	if err != nil {
+		return err
+	}
+	latlng := loc.LatLng()
+	anchor, dist, ok := nearestAnchor(d.opts.Anchors, latlng, t)
+	if !ok {
+		return nil
+	}
+	day := date(t.In(anchor.zone(d.opts.TimeZone)))
+	td := d.days[day]
+	if td == nil {
+		td = &tripDay{}
+		d.days[day] = td
+	}
+	if dist >= td.maxDistance {
+		td.maxDistance, td.farthest = dist, latlng
+	}
+	if d.prev != nil {
+		td.pathLength += earth.LengthFromAngle(d.prev.Distance(latlng))
+	}
+	d.prev = &latlng
+	return nil
+}
+
+// Trips returns all trips, ordered by time.
+func (d *TripDetector) Trips() []Trip {
+	days := make([]time.Time, 0, len(d.days))
+	for day := range d.days {
+		days = append(days, day)
+	}
+	slices.SortFunc(days, func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+	res := make([]Trip, 0)
+	var current *Trip
+	for _, day := range days {
+		td := d.days[day]
+		if td.maxDistance < d.opts.MinDistance {
+			if current != nil {
+				res = append(res, *current)
+				current = nil
+			}
+			continue
+		}
+		if current == nil {
+			current = &Trip{Start: day}
+		}
+		current.End = day
+		current.PathLength += td.pathLength
+		if td.maxDistance > current.MaxDistance {
+			current.MaxDistance, current.Farthest = td.maxDistance, td.farthest
+		}
+	}
+	if current != nil {
+		res = append(res, *current)
+	}
+	return res
+}
+
+// DetectTrips detects trips from the given locations, see TripDetector.
+func DetectTrips(locations []reader.Location, opts TripOptions) []Trip {
+	d := NewTripDetector(opts)
+	for _, loc := range locations {
+		d.Add(loc)
+	}
+	return d.Trips()
+}
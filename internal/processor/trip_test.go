@@ -0,0 +1,52 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-units/unit"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+func TestDetectTrips(t *testing.T) {
+	start := time.Date(2014, 4, 1, 12, 0, 0, 0, time.UTC)
+	locations := []reader.Location{
+		location(start, 47, 8),
+		// Two day trip, one degree north is about 111km.
+		location(start.AddDate(0, 0, 1), 48, 8),
+		location(start.AddDate(0, 0, 1).Add(time.Hour), 49, 8),
+		location(start.AddDate(0, 0, 2), 48, 8),
+		location(start.AddDate(0, 0, 3), 47, 8),
+		// Trip with a day without data in between.
+		location(start.AddDate(0, 0, 5), 48, 8),
+		location(start.AddDate(0, 0, 7), 48, 8),
+	}
+	want := []Trip{
+		{
+			Start:       parseDate(t, "2014-04-02"),
+			End:         parseDate(t, "2014-04-03"),
+			MaxDistance: 222.390 * unit.Kilometer,
+			Farthest:    s2.LatLngFromDegrees(49, 8),
+			PathLength:  333.585 * unit.Kilometer,
+		}, {
+			Start:       parseDate(t, "2014-04-06"),
+			End:         parseDate(t, "2014-04-08"),
+			MaxDistance: 111.195 * unit.Kilometer,
+			Farthest:    s2.LatLngFromDegrees(48, 8),
+			PathLength:  111.195 * unit.Kilometer,
+		},
+	}
+	got := DetectTrips(locations, TripOptions{
+		Anchors:     []Anchor{{Location: s2.LatLngFromDegrees(47, 8)}},
+		MinDistance: 50 * unit.Kilometer,
+	})
+	if diff := cmp.Diff(got, want, toKilometers, approxLatLng, cmpopts.EquateApprox(0.001, 0.001)); diff != "" {
+		t.Errorf("DetectTrips() = %v, want %v. Diff: %v", got, want, diff)
+	}
+	if days := got[1].Days(); days != 3 {
+		t.Errorf("Days() = %d, want 3", days)
+	}
+}
@@ -0,0 +1,50 @@
+package reader
+
+import (
+	"slices"
+	"time"
+)
+
+// Activities is the result of an activity recognition, listing all candidate activities.
+type Activities struct {
+	Timestamp   string     `json:"timestamp,omitempty"`
+	TimestampMs string     `json:"timestampMs,omitempty"`
+	Activity    []Activity `json:"activity"`
+}
+
+// Activity is a candidate activity with the confidence in percent.
+type Activity struct {
+	Type       string `json:"type"` // IN_VEHICLE, ON_BICYCLE, ON_FOOT, WALKING, RUNNING, STILL, TILTING, UNKNOWN, ...
+	Confidence int    `json:"confidence"`
+}
+
+// ParsedTimestamp returns the time of the recognition. Older exports use milliseconds since epoch.
+func (a Activities) ParsedTimestamp() (time.Time, error) {
+	return parseSemanticTimestamp(a.Timestamp, a.TimestampMs)
+}
+
+// Dominant returns the activity with the highest confidence. On ties, the one listed first wins.
+// Returns false if there are no candidates.
+func (a Activities) Dominant() (Activity, bool) {
+	if len(a.Activity) == 0 {
+		return Activity{}, false
+	}
+	// MaxFunc returns the first maximal element.
+	return slices.MaxFunc(a.Activity, func(x, y Activity) int {
+		return x.Confidence - y.Confidence
+	}), true
+}
+
+// DominantActivity returns the activity with the highest confidence over all recognitions of the location.
+// Returns false if the location has no activity.
+func (l Location) DominantActivity() (Activity, bool) {
+	var res Activity
+	found := false
+	for _, a := range l.Activity {
+		d, ok := a.Dominant()
+		if ok && (!found || d.Confidence > res.Confidence) {
+			res, found = d, true
+		}
+	}
+	return res, found
+}
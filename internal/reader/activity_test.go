@@ -0,0 +1,41 @@
+package reader
+
+import (
+	"testing"
+)
+
+func TestDominantActivity(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		loc       Location
+		want      Activity
+		wantFound bool
+	}{
+		{
+			name: "No activity",
+			loc:  Location{},
+		}, {
+			name: "Highest confidence over all recognitions",
+			loc: Location{Activity: []Activities{
+				{Activity: []Activity{{Type: "STILL", Confidence: 60}, {Type: "TILTING", Confidence: 40}}},
+				{Activity: []Activity{{Type: "ON_FOOT", Confidence: 30}, {Type: "IN_VEHICLE", Confidence: 70}}},
+			}},
+			want:      Activity{Type: "IN_VEHICLE", Confidence: 70},
+			wantFound: true,
+		}, {
+			name: "First one wins on ties",
+			loc: Location{Activity: []Activities{
+				{Activity: []Activity{{Type: "IN_VEHICLE", Confidence: 80}, {Type: "IN_ROAD_VEHICLE", Confidence: 80}}},
+			}},
+			want:      Activity{Type: "IN_VEHICLE", Confidence: 80},
+			wantFound: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, found := tc.loc.DominantActivity()
+			if got != tc.want || found != tc.wantFound {
+				t.Errorf("DominantActivity() = %v, %t, want %v, %t", got, found, tc.want, tc.wantFound)
+			}
+		})
+	}
+}
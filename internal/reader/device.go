@@ -0,0 +1,149 @@
+package reader
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeviceRange selects a device as the primary one, starting at a given time until the start of the next range.
+type DeviceRange struct {
+	StartTime time.Time
+	DeviceTag int
+}
+
+// ParseDeviceRanges parses a string as device ranges. The required format is
+// deviceTag or date,deviceTag:date2,deviceTag2
+func ParseDeviceRanges(devices string) ([]DeviceRange, error) {
+	split := strings.Split(devices, ":")
+	if len(split) == 1 && !strings.Contains(split[0], ",") {
+		tag, err := strconv.Atoi(split[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing device tag from %q: %w", split[0], err)
+		}
+		return []DeviceRange{{DeviceTag: tag}}, nil
+	}
+	res := make([]DeviceRange, 0, len(split))
+	for _, s := range split {
+		date, tagString, found := strings.Cut(s, ",")
+		if !found {
+			return nil, fmt.Errorf("dated device %q does not contain a comma", s)
+		}
+		t, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing date from %q: %w", s, err)
+		}
+		tag, err := strconv.Atoi(tagString)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing device tag from %q: %w", s, err)
+		}
+		res = append(res, DeviceRange{StartTime: t, DeviceTag: tag})
+	}
+	return res, nil
+}
+
+// CreateDeviceFilter returns a filter that only accepts locations reported by the primary device of their time.
+// Locations before the first range are matched against the first range. Locations without device tag, e.g. from
+// GPX files, are always accepted, as are all locations if there are no ranges. Assumes ranges are ordered by StartTime.
+func CreateDeviceFilter(ranges []DeviceRange) func(Location) bool {
+	return func(loc Location) bool {
+		if loc.DeviceTag == 0 || len(ranges) == 0 {
+			return true
+		}
+		t, err := loc.ParsedTimestamp()
+		if err != nil {
+			// Potentially log error.
+			return false
+		}
+		i := sort.Search(len(ranges), func(i int) bool {
+			return !t.After(ranges[i].StartTime)
+		})
+		return ranges[max(i-1, 0)].DeviceTag == loc.DeviceTag
+	}
+}
+
+// CreateFormFactorFilter returns a filter that only accepts locations reported by one of the given form factors,
+// e.g. PHONE or TABLET.
+func CreateFormFactorFilter(formFactors ...string) func(Location) bool {
+	return func(loc Location) bool {
+		return slices.Contains(formFactors, loc.FormFactor)
+	}
+}
+
+// DeviceCounter counts the locations reported by each device per time range for choosing the primary device.
+// Like DecodeJsonFunc, it allows processing a stream of locations.
+type DeviceCounter struct {
+	rangeStart func(time.Time) time.Time
+	counts     map[time.Time]map[int]int
+}
+
+// MonthStart returns the start of the calendar month of t in UTC, e.g. for counting devices per month.
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// NewDeviceCounter creates an empty DeviceCounter for time ranges starting at the time returned by rangeStart for
+// every location, e.g. MonthStart.
+func NewDeviceCounter(rangeStart func(time.Time) time.Time) *DeviceCounter {
+	return &DeviceCounter{
+		rangeStart: rangeStart,
+		counts:     make(map[time.Time]map[int]int),
+	}
+}
+
+// Add counts the given location for its device. Locations without device tag are ignored.
+func (c *DeviceCounter) Add(loc Location) error {
+	if loc.DeviceTag == 0 {
+		return nil
+	}
+	t, err := loc.ParsedTimestamp()
+	if err != nil {
+		return err
+	}
+	r := c.rangeStart(t)
+	if c.counts[r] == nil {
+		c.counts[r] = make(map[int]int)
+	}
+	c.counts[r][loc.DeviceTag]++
+	return nil
+}
+
+// PrimaryDevices returns the device with the most locations for every time range. Consecutive ranges with
+// the same device are combined. On ties, the smaller device tag is chosen to get a stable result.
+func (c *DeviceCounter) PrimaryDevices() []DeviceRange {
+	starts := make([]time.Time, 0, len(c.counts))
+	for t := range c.counts {
+		starts = append(starts, t)
+	}
+	slices.SortFunc(starts, func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+	res := make([]DeviceRange, 0)
+	for _, t := range starts {
+		primary, primaryCount := 0, 0
+		for tag, count := range c.counts[t] {
+			if count > primaryCount || (count == primaryCount && tag < primary) {
+				primary, primaryCount = tag, count
+			}
+		}
+		if len(res) > 0 && res[len(res)-1].DeviceTag == primary {
+			continue
+		}
+		res = append(res, DeviceRange{StartTime: t, DeviceTag: primary})
+	}
+	return res
+}
+
+// PrimaryDevices returns the device with the most locations for every time range starting at the time returned by
+// rangeStart. See DeviceCounter for details.
+func PrimaryDevices(locations []Location, rangeStart func(time.Time) time.Time) []DeviceRange {
+	c := NewDeviceCounter(rangeStart)
+	for _, loc := range locations {
+		c.Add(loc)
+	}
+	return c.PrimaryDevices()
+}
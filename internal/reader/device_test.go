@@ -0,0 +1,106 @@
+package reader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDeviceRanges(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		input   string
+		want    []DeviceRange
+		wantErr bool
+	}{
+		{
+			name:  "Single device",
+			input: "-123",
+			want:  []DeviceRange{{DeviceTag: -123}},
+		}, {
+			name:  "Dated devices",
+			input: "2014-01-01,1:2016-02-01,2",
+			want: []DeviceRange{
+				{StartTime: time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC), DeviceTag: 1},
+				{StartTime: time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC), DeviceTag: 2},
+			},
+		}, {
+			name:  "Single dated device",
+			input: "2014-01-01,1",
+			want:  []DeviceRange{{StartTime: time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC), DeviceTag: 1}},
+		}, {
+			name:    "Invalid tag",
+			input:   "phone",
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseDeviceRanges(tc.input)
+			if (err != nil) != tc.wantErr || !cmp.Equal(got, tc.want) {
+				t.Errorf("ParseDeviceRanges(%q) = %v, %v, want %v", tc.input, got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestCreateDeviceFilter(t *testing.T) {
+	filter := CreateDeviceFilter([]DeviceRange{
+		{StartTime: time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC), DeviceTag: 1},
+		{StartTime: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), DeviceTag: 2},
+	})
+	for _, tc := range []struct {
+		loc  Location
+		want bool
+	}{
+		{loc: Location{Timestamp: "2015-01-01T00:00:00Z", DeviceTag: 1}, want: true},
+		{loc: Location{Timestamp: "2015-01-01T00:00:00Z", DeviceTag: 2}, want: false},
+		{loc: Location{Timestamp: "2017-01-01T00:00:00Z", DeviceTag: 2}, want: true},
+		{loc: Location{Timestamp: "2013-01-01T00:00:00Z", DeviceTag: 1}, want: true},
+		{loc: Location{Timestamp: "2015-01-01T00:00:00Z"}, want: true},
+	} {
+		if got := filter(tc.loc); got != tc.want {
+			t.Errorf("filter(%v) = %t, want %t", tc.loc, got, tc.want)
+		}
+	}
+}
+
+func TestPrimaryDevices(t *testing.T) {
+	locations := []Location{
+		{Timestamp: "2020-01-01T08:00:00Z", DeviceTag: 1},
+		{Timestamp: "2020-01-01T09:00:00Z", DeviceTag: 2},
+		{Timestamp: "2020-01-01T10:00:00Z", DeviceTag: 2},
+		{Timestamp: "2020-01-02T10:00:00Z", DeviceTag: 2},
+		{Timestamp: "2020-01-03T10:00:00Z", DeviceTag: 1},
+		{Timestamp: "2020-01-03T11:00:00Z"},
+		{Timestamp: "2020-01-03T12:00:00Z"},
+	}
+	want := []DeviceRange{
+		{StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), DeviceTag: 2},
+		{StartTime: time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC), DeviceTag: 1},
+	}
+	got := PrimaryDevices(locations, func(t time.Time) time.Time { return t.Truncate(24 * time.Hour) })
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("PrimaryDevices() = %v, want %v. Diff: %v", got, want, diff)
+	}
+}
+
+func TestPrimaryDevicesMonthly(t *testing.T) {
+	locations := []Location{
+		{Timestamp: "2020-01-31T23:00:00Z", DeviceTag: 1},
+		{Timestamp: "2020-02-01T01:00:00Z", DeviceTag: 2},
+		{Timestamp: "2020-02-29T23:00:00Z", DeviceTag: 2},
+		// Still February in UTC.
+		{Timestamp: "2020-03-01T00:30:00+01:00", DeviceTag: 1},
+		{Timestamp: "2020-03-01T01:00:00Z", DeviceTag: 3},
+	}
+	want := []DeviceRange{
+		{StartTime: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), DeviceTag: 1},
+		{StartTime: time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC), DeviceTag: 2},
+		{StartTime: time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC), DeviceTag: 3},
+	}
+	got := PrimaryDevices(locations, MonthStart)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("PrimaryDevices() diff (-want +got):\n%s", diff)
+	}
+}
@@ -1,6 +1,14 @@
 package reader
 
-import "time"
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang/geo/earth"
+	"github.com/google/go-units/unit"
+)
 
 func FilterFunc(locations []Location, filter func(Location) bool) ([]Location, error) {
 	res := make([]Location, 0)
@@ -21,3 +29,103 @@ func CreateDateFilter(first, last time.Time) func(Location) bool {
 		return false
 	}
 }
+
+// StreamFilterFunc returns a function that passes on only locations matching the filter to fn.
+// This is the streaming counterpart of FilterFunc and can be used directly with DecodeJsonFunc.
+func StreamFilterFunc(filter func(Location) bool, fn func(Location) error) func(Location) error {
+	return func(loc Location) error {
+		if !filter(loc) {
+			return nil
+		}
+		return fn(loc)
+	}
+}
+
+// CreateAccuracyFilter returns a filter that only accepts locations with an accuracy of at most maxAccuracy meters.
+// Locations without accuracy, e.g. from GPX files, are accepted.
+func CreateAccuracyFilter(maxAccuracy int) func(Location) bool {
+	return func(loc Location) bool {
+		return loc.Accuracy <= maxAccuracy
+	}
+}
+
+// CreateSourceFilter returns a filter that only accepts locations from one of the given sources, e.g. GPS, WIFI or
+// CELL. Sources are compared case-insensitively. Locations without source are accepted.
+func CreateSourceFilter(sources ...string) func(Location) bool {
+	return func(loc Location) bool {
+		if loc.Source == "" {
+			return true
+		}
+		return slices.ContainsFunc(sources, func(s string) bool {
+			return strings.EqualFold(s, loc.Source)
+		})
+	}
+}
+
+// StreamTeleportFilterFunc returns a function that passes on locations to fn, dropping outliers that imply an
+// impossible speed. A location is an outlier if the speed needed to get there from the previous location and
+// the speed needed to get from there to the next location both exceed maxSpeed. As this requires knowing the next
+// location, locations are passed on with a delay of one; flush must be called after the last location.
+// Locations are expected to be ordered by time, e.g. as passed on by DecodeFunc, as outliers are found by comparing
+// neighbors. Locations with unparseable timestamps are dropped.
+func StreamTeleportFilterFunc(maxSpeed unit.Speed, fn func(Location) error) (filter func(Location) error, flush func() error) {
+	var prev, candidate *timedLocation
+	filter = func(loc Location) error {
+		t, err := loc.ParsedTimestamp()
+		if err != nil {
+			// Potentially log error.
+			return nil
+		}
+		next := &timedLocation{Location: loc, t: t}
+		if candidate != nil {
+			if prev != nil && speed(*prev, *candidate) > maxSpeed && speed(*candidate, *next) > maxSpeed {
+				// Drop the candidate, keep the previous location for comparing with the next one.
+				candidate = next
+				return nil
+			}
+			if err := fn(candidate.Location); err != nil {
+				return err
+			}
+			prev = candidate
+		}
+		candidate = next
+		return nil
+	}
+	flush = func() error {
+		if candidate == nil {
+			return nil
+		}
+		c := candidate
+		candidate = nil
+		return fn(c.Location)
+	}
+	return filter, flush
+}
+
+// RejectTeleports drops outliers that imply an impossible speed, see StreamTeleportFilterFunc.
+func RejectTeleports(locations []Location, maxSpeed unit.Speed) []Location {
+	res := make([]Location, 0, len(locations))
+	filter, flush := StreamTeleportFilterFunc(maxSpeed, func(loc Location) error {
+		res = append(res, loc)
+		return nil
+	})
+	for _, loc := range locations {
+		filter(loc)
+	}
+	flush()
+	return res
+}
+
+// speed returns the speed needed for getting from a to b. The order of a and b does not matter, so a location
+// out of order is not mistaken for an outlier just because of its timestamp.
+func speed(a, b timedLocation) unit.Speed {
+	dist := earth.LengthFromAngle(a.LatLng().Distance(b.LatLng()))
+	d := b.t.Sub(a.t).Abs()
+	if d == 0 {
+		if dist == 0 {
+			return 0
+		}
+		return unit.Speed(math.Inf(1))
+	}
+	return unit.Speed(dist.Meters()/d.Seconds()) * unit.MeterPerSecond
+}
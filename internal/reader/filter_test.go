@@ -0,0 +1,146 @@
+package reader
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-units/unit"
+)
+
+func TestCreateAccuracyFilter(t *testing.T) {
+	filter := CreateAccuracyFilter(100)
+	for _, tc := range []struct {
+		loc  Location
+		want bool
+	}{
+		{loc: Location{Accuracy: 5}, want: true},
+		{loc: Location{Accuracy: 100}, want: true},
+		{loc: Location{Accuracy: 2000}, want: false},
+		{loc: Location{}, want: true},
+	} {
+		if got := filter(tc.loc); got != tc.want {
+			t.Errorf("filter(%v) = %t, want %t", tc.loc, got, tc.want)
+		}
+	}
+}
+
+func TestCreateSourceFilter(t *testing.T) {
+	filter := CreateSourceFilter("GPS", "WIFI")
+	for _, tc := range []struct {
+		loc  Location
+		want bool
+	}{
+		{loc: Location{Source: "GPS"}, want: true},
+		{loc: Location{Source: "wifi"}, want: true},
+		{loc: Location{Source: "CELL"}, want: false},
+		{loc: Location{}, want: true},
+	} {
+		if got := filter(tc.loc); got != tc.want {
+			t.Errorf("filter(%v) = %t, want %t", tc.loc, got, tc.want)
+		}
+	}
+}
+
+func TestRejectTeleports(t *testing.T) {
+	maxSpeed := 300 * unit.KilometerPerHour
+	for _, tc := range []struct {
+		name      string
+		locations []Location
+		want      []Location
+	}{
+		{
+			name:      "Empty",
+			locations: []Location{},
+			want:      []Location{},
+		}, {
+			name: "Drops single outlier",
+			locations: []Location{
+				{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 470000000},
+				// About 111km away within a minute.
+				{Timestamp: "2020-01-01T10:01:00Z", LatitudeE7: 480000000},
+				{Timestamp: "2020-01-01T10:02:00Z", LatitudeE7: 470001000},
+			},
+			want: []Location{
+				{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 470000000},
+				{Timestamp: "2020-01-01T10:02:00Z", LatitudeE7: 470001000},
+			},
+		}, {
+			name: "Keeps plausible trip",
+			locations: []Location{
+				{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 470000000},
+				{Timestamp: "2020-01-01T11:00:00Z", LatitudeE7: 480000000},
+				{Timestamp: "2020-01-01T12:00:00Z", LatitudeE7: 490000000},
+			},
+			want: []Location{
+				{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 470000000},
+				{Timestamp: "2020-01-01T11:00:00Z", LatitudeE7: 480000000},
+				{Timestamp: "2020-01-01T12:00:00Z", LatitudeE7: 490000000},
+			},
+		}, {
+			name: "Keeps fast move without return",
+			locations: []Location{
+				{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 470000000},
+				{Timestamp: "2020-01-01T10:01:00Z", LatitudeE7: 480000000},
+				{Timestamp: "2020-01-01T10:02:00Z", LatitudeE7: 480001000},
+			},
+			want: []Location{
+				{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 470000000},
+				{Timestamp: "2020-01-01T10:01:00Z", LatitudeE7: 480000000},
+				{Timestamp: "2020-01-01T10:02:00Z", LatitudeE7: 480001000},
+			},
+		}, {
+			name: "Keeps location out of order",
+			locations: []Location{
+				{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 470000000},
+				{Timestamp: "2020-01-01T09:59:00Z", LatitudeE7: 470010000},
+				{Timestamp: "2020-01-01T09:58:00Z", LatitudeE7: 470020000},
+			},
+			want: []Location{
+				{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 470000000},
+				{Timestamp: "2020-01-01T09:59:00Z", LatitudeE7: 470010000},
+				{Timestamp: "2020-01-01T09:58:00Z", LatitudeE7: 470020000},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := RejectTeleports(tc.locations, maxSpeed)
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("RejectTeleports() = %v, want %v. Diff: %v", got, tc.want, diff)
+			}
+		})
+	}
+}
+
+func TestRejectTeleportsFromTimeline(t *testing.T) {
+	// Raw signals of Timeline.json come after the semantic segments, although they are recorded in between.
+	input := `{
+	"semanticSegments": [{
+		"startTime": "2024-01-01T08:00:00.000Z",
+		"endTime": "2024-01-01T09:00:00.000Z",
+		"timelinePath": [
+			{"point": "47.00°, 8.00°", "time": "2024-01-01T08:00:00.000Z"},
+			{"point": "47.20°, 8.00°", "time": "2024-01-01T09:00:00.000Z"}
+		]
+	}],
+	"rawSignals": [
+		{"position": {"LatLng": "47.10°, 8.00°", "timestamp": "2024-01-01T08:30:00.000Z"}},
+		{"position": {"LatLng": "49.00°, 8.00°", "timestamp": "2024-01-01T08:31:00.000Z"}}
+	]
+}`
+	var got []string
+	filter, flush := StreamTeleportFilterFunc(300*unit.KilometerPerHour, func(loc Location) error {
+		got = append(got, loc.Timestamp)
+		return nil
+	})
+	if err := DecodeFunc("Timeline.json", strings.NewReader(input), filter); err != nil {
+		t.Fatal(err)
+	}
+	if err := flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"2024-01-01T08:00:00.000Z", "2024-01-01T08:30:00.000Z", "2024-01-01T09:00:00.000Z"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("kept timestamps diff (-want +got):\n%s", diff)
+	}
+}
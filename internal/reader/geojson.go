@@ -0,0 +1,215 @@
+package reader
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"time"
+)
+
+type geoJsonFeature struct {
+	Type     string `json:"type"`
+	Geometry struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+	// Times are either strings or numbers, see parseGeoJsonTime.
+	Properties struct {
+		Time      json.RawMessage `json:"time"`
+		Timestamp json.RawMessage `json:"timestamp"`
+		Times     json.RawMessage `json:"times"`
+		// CoordTimes is used by popular converters like togeojson. For MultiLineString, e.g. a GPX track with several
+		// segments, it contains one array per line.
+		CoordTimes json.RawMessage `json:"coordTimes"`
+	} `json:"properties"`
+}
+
+type geoJsonDocument struct {
+	geoJsonFeature
+	Features []geoJsonFeature `json:"features"`
+}
+
+// DecodeGeoJsonFunc reads GeoJSON from the given reader and calls fn for every timestamped point. Both a
+// FeatureCollection and a single Feature are supported. Times are taken from the feature's properties:
+// * Point features use "time" or "timestamp",
+// * MultiPoint and LineString features use "coordTimes" or "times", with one entry per coordinate,
+// * MultiLineString features use "coordTimes" or "times", with one array of times per line.
+// Times are either strings in RFC 3339 format or numbers of seconds or milliseconds since the epoch.
+// Features without time are skipped. Features that can't be parsed are logged and skipped as well.
+func DecodeGeoJsonFunc(reader io.Reader, fn func(Location) error) error {
+	var doc geoJsonDocument
+	if err := json.NewDecoder(reader).Decode(&doc); err != nil {
+		return err
+	}
+	features := doc.Features
+	if doc.Type == "Feature" {
+		features = []geoJsonFeature{doc.geoJsonFeature}
+	}
+	for i, f := range features {
+		locations, err := f.locations()
+		if err != nil {
+			log.Default().Printf("Skipping GeoJSON feature %d: %v", i, err)
+			continue
+		}
+		for _, loc := range locations {
+			if err := fn(loc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (f geoJsonFeature) locations() ([]Location, error) {
+	switch f.Geometry.Type {
+	case "Point":
+		var coords []float64
+		if err := json.Unmarshal(f.Geometry.Coordinates, &coords); err != nil {
+			return nil, fmt.Errorf("decoding Point coordinates: %w", err)
+		}
+		ts, err := parseGeoJsonTime(f.Properties.Time)
+		if err != nil {
+			return nil, err
+		}
+		if ts == "" {
+			if ts, err = parseGeoJsonTime(f.Properties.Timestamp); err != nil {
+				return nil, err
+			}
+		}
+		if ts == "" {
+			return nil, nil
+		}
+		loc, err := geoJsonLocation(coords, ts)
+		if err != nil {
+			return nil, err
+		}
+		return []Location{loc}, nil
+	case "MultiPoint", "LineString":
+		var coords [][]float64
+		if err := json.Unmarshal(f.Geometry.Coordinates, &coords); err != nil {
+			return nil, fmt.Errorf("decoding %s coordinates: %w", f.Geometry.Type, err)
+		}
+		times, err := parseGeoJsonTimes(f.coordTimes())
+		if err != nil {
+			return nil, err
+		}
+		return geoJsonLine(f.Geometry.Type, coords, times)
+	case "MultiLineString":
+		var lines [][][]float64
+		if err := json.Unmarshal(f.Geometry.Coordinates, &lines); err != nil {
+			return nil, fmt.Errorf("decoding MultiLineString coordinates: %w", err)
+		}
+		raw := f.coordTimes()
+		if len(raw) == 0 || string(raw) == "null" {
+			return nil, nil
+		}
+		var lineTimes []json.RawMessage
+		if err := json.Unmarshal(raw, &lineTimes); err != nil {
+			return nil, fmt.Errorf("decoding MultiLineString times: %w", err)
+		}
+		if len(lineTimes) != len(lines) {
+			return nil, fmt.Errorf("MultiLineString has %d arrays of times, but %d lines", len(lineTimes), len(lines))
+		}
+		var res []Location
+		for i, coords := range lines {
+			times, err := parseGeoJsonTimes(lineTimes[i])
+			if err != nil {
+				return nil, err
+			}
+			locations, err := geoJsonLine("MultiLineString", coords, times)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, locations...)
+		}
+		return res, nil
+	}
+	return nil, nil
+}
+
+// coordTimes returns the times per coordinate, from "coordTimes" or "times".
+func (f geoJsonFeature) coordTimes() json.RawMessage {
+	if len(f.Properties.CoordTimes) > 0 && string(f.Properties.CoordTimes) != "null" {
+		return f.Properties.CoordTimes
+	}
+	return f.Properties.Times
+}
+
+// geoJsonLine converts the positions of a line to locations with the given times. Lines without times are skipped.
+func geoJsonLine(geometry string, coords [][]float64, times []string) ([]Location, error) {
+	if len(times) == 0 {
+		return nil, nil
+	}
+	if len(times) != len(coords) {
+		return nil, fmt.Errorf("%s has %d times, but %d coordinates", geometry, len(times), len(coords))
+	}
+	res := make([]Location, 0, len(coords))
+	for i, c := range coords {
+		loc, err := geoJsonLocation(c, times[i])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, loc)
+	}
+	return res, nil
+}
+
+// parseGeoJsonTimes parses an array of times, see parseGeoJsonTime. Returns nil if raw is empty.
+func parseGeoJsonTimes(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var values []json.RawMessage
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil, fmt.Errorf("decoding times: %w", err)
+	}
+	res := make([]string, 0, len(values))
+	for _, v := range values {
+		ts, err := parseGeoJsonTime(v)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ts)
+	}
+	return res, nil
+}
+
+// parseGeoJsonTime parses a time, either a string that is returned as is, or a number of seconds or milliseconds
+// since the epoch that is converted to RFC 3339. Numbers of at least 1e11 are taken as milliseconds, as they would be
+// thousands of years in the future as seconds. Returns an empty string if raw is empty.
+func parseGeoJsonTime(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	var n float64
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return "", fmt.Errorf("time %s is neither a string nor a number", raw)
+	}
+	t := time.UnixMilli(int64(math.Round(n * 1000)))
+	if math.Abs(n) >= 1e11 {
+		t = time.UnixMilli(int64(math.Round(n)))
+	}
+	return t.UTC().Format(time.RFC3339Nano), nil
+}
+
+// geoJsonLocation converts a GeoJSON position, i.e. [lng, lat, alt], to a location.
+func geoJsonLocation(coords []float64, ts string) (Location, error) {
+	if len(coords) < 2 {
+		return Location{}, fmt.Errorf("position %v has less than two values", coords)
+	}
+	loc := Location{
+		Timestamp:   ts,
+		LatitudeE7:  degreesToE7(coords[1]),
+		LongitudeE7: degreesToE7(coords[0]),
+	}
+	if len(coords) > 2 {
+		loc.Altitude = int(math.Round(coords[2]))
+	}
+	return loc, nil
+}
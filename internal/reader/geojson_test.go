@@ -0,0 +1,117 @@
+package reader
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeGeoJson(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input string
+		want  []Location
+	}{
+		{
+			name: "FeatureCollection",
+			input: `{
+				"type": "FeatureCollection",
+				"features": [{
+					"type": "Feature",
+					"geometry": {"type": "Point", "coordinates": [7.4474, 46.9480, 540]},
+					"properties": {"time": "2020-05-01T08:00:00Z"}
+				}, {
+					"type": "Feature",
+					"geometry": {"type": "Point", "coordinates": [7.4474, 46.9480]},
+					"properties": {"name": "Without time"}
+				}, {
+					"type": "Feature",
+					"geometry": {"type": "LineString", "coordinates": [[8.5417, 47.3769], [8.5418, 47.3770]]},
+					"properties": {"coordTimes": ["2020-05-01T09:00:00Z", "2020-05-01T09:00:05Z"]}
+				}]
+			}`,
+			want: []Location{
+				{Timestamp: "2020-05-01T08:00:00Z", LatitudeE7: 469480000, LongitudeE7: 74474000, Altitude: 540},
+				{Timestamp: "2020-05-01T09:00:00Z", LatitudeE7: 473769000, LongitudeE7: 85417000},
+				{Timestamp: "2020-05-01T09:00:05Z", LatitudeE7: 473770000, LongitudeE7: 85418000},
+			},
+		}, {
+			name: "MultiLineString and MultiPoint",
+			input: `{
+				"type": "FeatureCollection",
+				"features": [{
+					"type": "Feature",
+					"geometry": {"type": "MultiLineString", "coordinates": [[[8.5417, 47.3769], [8.5418, 47.3770]], [[8.5419, 47.3771]]]},
+					"properties": {"coordTimes": [["2020-05-01T09:00:00Z", "2020-05-01T09:00:05Z"], ["2020-05-01T09:10:00Z"]]}
+				}, {
+					"type": "Feature",
+					"geometry": {"type": "MultiPoint", "coordinates": [[7.4474, 46.9480], [7.4475, 46.9481]]},
+					"properties": {"times": ["2020-05-01T10:00:00Z", "2020-05-01T10:05:00Z"]}
+				}]
+			}`,
+			want: []Location{
+				{Timestamp: "2020-05-01T09:00:00Z", LatitudeE7: 473769000, LongitudeE7: 85417000},
+				{Timestamp: "2020-05-01T09:00:05Z", LatitudeE7: 473770000, LongitudeE7: 85418000},
+				{Timestamp: "2020-05-01T09:10:00Z", LatitudeE7: 473771000, LongitudeE7: 85419000},
+				{Timestamp: "2020-05-01T10:00:00Z", LatitudeE7: 469480000, LongitudeE7: 74474000},
+				{Timestamp: "2020-05-01T10:05:00Z", LatitudeE7: 469481000, LongitudeE7: 74475000},
+			},
+		}, {
+			name: "Epoch times",
+			input: `{
+				"type": "FeatureCollection",
+				"features": [{
+					"type": "Feature",
+					"geometry": {"type": "Point", "coordinates": [7.4474, 46.9480]},
+					"properties": {"time": 1588320000}
+				}, {
+					"type": "Feature",
+					"geometry": {"type": "LineString", "coordinates": [[8.5417, 47.3769], [8.5418, 47.3770]]},
+					"properties": {"times": [1588323600000, 1588323605500]}
+				}]
+			}`,
+			want: []Location{
+				{Timestamp: "2020-05-01T08:00:00Z", LatitudeE7: 469480000, LongitudeE7: 74474000},
+				{Timestamp: "2020-05-01T09:00:00Z", LatitudeE7: 473769000, LongitudeE7: 85417000},
+				{Timestamp: "2020-05-01T09:00:05.5Z", LatitudeE7: 473770000, LongitudeE7: 85418000},
+			},
+		}, {
+			name: "Skips invalid features",
+			input: `{
+				"type": "FeatureCollection",
+				"features": [{
+					"type": "Feature",
+					"geometry": {"type": "LineString", "coordinates": [[8.5417, 47.3769], [8.5418, 47.3770]]},
+					"properties": {"coordTimes": ["2020-05-01T09:00:00Z"]}
+				}, {
+					"type": "Feature",
+					"geometry": {"type": "Point", "coordinates": [7.4474, 46.9480]},
+					"properties": {"time": {"value": "2020-05-01T09:30:00Z"}}
+				}, {
+					"type": "Feature",
+					"geometry": {"type": "Point", "coordinates": [7.4474, 46.9480]},
+					"properties": {"time": "2020-05-01T10:00:00Z"}
+				}]
+			}`,
+			want: []Location{
+				{Timestamp: "2020-05-01T10:00:00Z", LatitudeE7: 469480000, LongitudeE7: 74474000},
+			},
+		}, {
+			name: "Single Feature",
+			input: `{
+				"type": "Feature",
+				"geometry": {"type": "Point", "coordinates": [7.4474, 46.9480]},
+				"properties": {"timestamp": "2020-05-01T08:00:00Z"}
+			}`,
+			want: []Location{
+				{Timestamp: "2020-05-01T08:00:00Z", LatitudeE7: 469480000, LongitudeE7: 74474000},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(collect(t, DecodeGeoJsonFunc, tc.input), tc.want); diff != "" {
+				t.Errorf("DecodeGeoJsonFunc() diff: %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package reader
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+)
+
+type gpxPoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Ele  float64 `xml:"ele"`
+	Time string  `xml:"time"`
+}
+
+// DecodeGpxFunc reads GPX from the given reader and calls fn for every track point, route point and waypoint.
+// Points without time are skipped, as they can not be placed on a timeline.
+func DecodeGpxFunc(reader io.Reader, fn func(Location) error) error {
+	decoder := xml.NewDecoder(reader)
+	for {
+		t, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		se, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch se.Name.Local {
+		case "trkpt", "rtept", "wpt":
+		default:
+			continue
+		}
+		var p gpxPoint
+		if err := decoder.DecodeElement(&p, &se); err != nil {
+			return err
+		}
+		if p.Time == "" {
+			continue
+		}
+		err = fn(Location{
+			Timestamp:   p.Time,
+			LatitudeE7:  degreesToE7(p.Lat),
+			LongitudeE7: degreesToE7(p.Lon),
+			Altitude:    int(math.Round(p.Ele)),
+			Source:      "GPS",
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
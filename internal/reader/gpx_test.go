@@ -0,0 +1,33 @@
+package reader
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeGpx(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1">
+  <wpt lat="46.9480" lon="7.4474">
+    <name>No time</name>
+  </wpt>
+  <wpt lat="46.9480" lon="7.4474">
+    <time>2020-05-01T08:00:00Z</time>
+  </wpt>
+  <trk>
+    <trkseg>
+      <trkpt lat="47.3769" lon="8.5417"><ele>408.4</ele><time>2020-05-01T09:00:00Z</time></trkpt>
+      <trkpt lat="47.3770" lon="8.5418"><time>2020-05-01T09:00:05Z</time></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+	want := []Location{
+		{Timestamp: "2020-05-01T08:00:00Z", LatitudeE7: 469480000, LongitudeE7: 74474000, Source: "GPS"},
+		{Timestamp: "2020-05-01T09:00:00Z", LatitudeE7: 473769000, LongitudeE7: 85417000, Altitude: 408, Source: "GPS"},
+		{Timestamp: "2020-05-01T09:00:05Z", LatitudeE7: 473770000, LongitudeE7: 85418000, Source: "GPS"},
+	}
+	if diff := cmp.Diff(collect(t, DecodeGpxFunc, input), want); diff != "" {
+		t.Errorf("DecodeGpxFunc() diff: %v", diff)
+	}
+}
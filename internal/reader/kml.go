@@ -0,0 +1,111 @@
+package reader
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type kmlPlacemark struct {
+	When  string `xml:"TimeStamp>when"`
+	Begin string `xml:"TimeSpan>begin"`
+	Point struct {
+		Coordinates string `xml:"coordinates"`
+	} `xml:"Point"`
+	// Tracks holds gx:Track elements, either directly in the placemark or inside a gx:MultiTrack.
+	Tracks      []kmlTrack `xml:"Track"`
+	MultiTracks []kmlTrack `xml:"MultiTrack>Track"`
+}
+
+type kmlTrack struct {
+	When  []string `xml:"when"`
+	Coord []string `xml:"coord"`
+}
+
+// DecodeKmlFunc reads KML from the given reader and calls fn for every timestamped point. Supported are
+// * Placemarks with a Point and a TimeStamp or TimeSpan, in which case the beginning of the span is used,
+// * gx:Track and gx:MultiTrack, where each coordinate is matched with its respective timestamp.
+func DecodeKmlFunc(reader io.Reader, fn func(Location) error) error {
+	decoder := xml.NewDecoder(reader)
+	for {
+		t, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		se, ok := t.(xml.StartElement)
+		if !ok || se.Name.Local != "Placemark" {
+			continue
+		}
+		var p kmlPlacemark
+		if err := decoder.DecodeElement(&p, &se); err != nil {
+			return err
+		}
+		if err := p.locations(fn); err != nil {
+			return err
+		}
+	}
+}
+
+func (p kmlPlacemark) locations(fn func(Location) error) error {
+	when := p.When
+	if when == "" {
+		when = p.Begin
+	}
+	if c := strings.TrimSpace(p.Point.Coordinates); c != "" && when != "" {
+		// Coordinates are given as lng,lat[,alt].
+		loc, err := kmlLocation(strings.Split(c, ","), when)
+		if err != nil {
+			return err
+		}
+		if err := fn(loc); err != nil {
+			return err
+		}
+	}
+	for _, track := range append(p.Tracks, p.MultiTracks...) {
+		if len(track.When) != len(track.Coord) {
+			return fmt.Errorf("gx:Track has %d timestamps, but %d coordinates", len(track.When), len(track.Coord))
+		}
+		for i, c := range track.Coord {
+			// Coordinates are given as "lng lat alt".
+			loc, err := kmlLocation(strings.Fields(c), track.When[i])
+			if err != nil {
+				return err
+			}
+			if err := fn(loc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func kmlLocation(coords []string, when string) (Location, error) {
+	if len(coords) < 2 {
+		return Location{}, fmt.Errorf("coordinates %q have less than two values", coords)
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(coords[0]), 64)
+	if err != nil {
+		return Location{}, fmt.Errorf("error parsing lng from %q: %w", coords, err)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(coords[1]), 64)
+	if err != nil {
+		return Location{}, fmt.Errorf("error parsing lat from %q: %w", coords, err)
+	}
+	loc := Location{
+		Timestamp:   strings.TrimSpace(when),
+		LatitudeE7:  degreesToE7(lat),
+		LongitudeE7: degreesToE7(lng),
+	}
+	if len(coords) > 2 {
+		if alt, err := strconv.ParseFloat(strings.TrimSpace(coords[2]), 64); err == nil {
+			loc.Altitude = int(math.Round(alt))
+		}
+	}
+	return loc, nil
+}
@@ -0,0 +1,40 @@
+package reader
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeKml(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:gx="http://www.google.com/kml/ext/2.2">
+  <Document>
+    <Placemark>
+      <name>Office</name>
+      <TimeStamp><when>2020-05-01T08:00:00Z</when></TimeStamp>
+      <Point><coordinates>7.4474,46.9480,540</coordinates></Point>
+    </Placemark>
+    <Placemark>
+      <name>Without time</name>
+      <Point><coordinates>7.4474,46.9480</coordinates></Point>
+    </Placemark>
+    <Placemark>
+      <gx:Track>
+        <when>2020-05-01T09:00:00Z</when>
+        <when>2020-05-01T09:00:05Z</when>
+        <gx:coord>8.5417 47.3769 408</gx:coord>
+        <gx:coord>8.5418 47.3770 409</gx:coord>
+      </gx:Track>
+    </Placemark>
+  </Document>
+</kml>`
+	want := []Location{
+		{Timestamp: "2020-05-01T08:00:00Z", LatitudeE7: 469480000, LongitudeE7: 74474000, Altitude: 540},
+		{Timestamp: "2020-05-01T09:00:00Z", LatitudeE7: 473769000, LongitudeE7: 85417000, Altitude: 408},
+		{Timestamp: "2020-05-01T09:00:05Z", LatitudeE7: 473770000, LongitudeE7: 85418000, Altitude: 409},
+	}
+	if diff := cmp.Diff(collect(t, DecodeKmlFunc, input), want); diff != "" {
+		t.Errorf("DecodeKmlFunc() diff: %v", diff)
+	}
+}
@@ -0,0 +1,92 @@
+package reader
+
+import (
+	"slices"
+	"time"
+
+	"github.com/golang/geo/earth"
+)
+
+type timedLocation struct {
+	Location
+	t time.Time
+}
+
+// Merge combines locations from multiple sources into a single timeline ordered by time.
+// Duplicates are dropped: two locations are considered the same if they have the same timestamp and their
+// coordinates are within the accuracy of either of them. Of duplicates, the first one passed in is kept.
+// Locations with unparseable timestamps are dropped as well.
+func Merge(sources ...[]Location) []Location {
+	n := 0
+	for _, s := range sources {
+		n += len(s)
+	}
+	all := make([]timedLocation, 0, n)
+	for _, s := range sources {
+		for _, loc := range s {
+			t, err := loc.ParsedTimestamp()
+			if err != nil {
+				continue
+			}
+			all = append(all, timedLocation{Location: loc, t: t})
+		}
+	}
+	slices.SortStableFunc(all, func(a, b timedLocation) int {
+		return a.t.Compare(b.t)
+	})
+
+	res := make([]Location, 0, len(all))
+	// Start of the kept locations sharing the timestamp of the current one.
+	group := 0
+	for i, loc := range all {
+		if i > 0 && !loc.t.Equal(all[i-1].t) {
+			group = len(res)
+		}
+		if !slices.ContainsFunc(res[group:], loc.isDuplicate) {
+			res = append(res, loc.Location)
+		}
+	}
+	return res
+}
+
+// isDuplicate returns true if other is within the accuracy of l. Timestamps are not compared.
+func (l timedLocation) isDuplicate(other Location) bool {
+	if l.LatitudeE7 == other.LatitudeE7 && l.LongitudeE7 == other.LongitudeE7 {
+		return true
+	}
+	return earth.LengthFromAngle(l.LatLng().Distance(other.LatLng())).Meters() <= float64(max(l.Accuracy, other.Accuracy))
+}
+
+// emitSorted calls decode with a function collecting all locations, then passes them on to fn ordered by time.
+// Locations with equal timestamps keep their order. Locations with unparseable timestamps are passed on last, so fn
+// can report them.
+func emitSorted(decode func(func(Location) error) error, fn func(Location) error) error {
+	var all []timedLocation
+	var invalid []Location
+	err := decode(func(loc Location) error {
+		t, err := loc.ParsedTimestamp()
+		if err != nil {
+			invalid = append(invalid, loc)
+			return nil
+		}
+		all = append(all, timedLocation{Location: loc, t: t})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	slices.SortStableFunc(all, func(a, b timedLocation) int {
+		return a.t.Compare(b.t)
+	})
+	for _, loc := range all {
+		if err := fn(loc.Location); err != nil {
+			return err
+		}
+	}
+	for _, loc := range invalid {
+		if err := fn(loc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
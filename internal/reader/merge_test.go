@@ -0,0 +1,78 @@
+package reader
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMerge(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		sources [][]Location
+		want    []Location
+	}{
+		{
+			name: "Orders by time",
+			sources: [][]Location{
+				{{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 1}},
+				{{Timestamp: "2020-01-01T09:00:00Z", LatitudeE7: 2}},
+			},
+			want: []Location{
+				{Timestamp: "2020-01-01T09:00:00Z", LatitudeE7: 2},
+				{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 1},
+			},
+		}, {
+			name: "Drops exact duplicates",
+			sources: [][]Location{
+				{{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 1, Source: "first"}},
+				{{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 1, Source: "second"}},
+			},
+			want: []Location{
+				{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 1, Source: "first"},
+			},
+		}, {
+			name: "Same instant in different notation is a duplicate",
+			sources: [][]Location{
+				{{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 1}},
+				{{Timestamp: "2020-01-01T11:00:00+01:00", LatitudeE7: 1}},
+			},
+			want: []Location{
+				{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 1},
+			},
+		}, {
+			name: "Drops near duplicates within accuracy",
+			sources: [][]Location{
+				// About 11m apart.
+				{{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 470000000, Accuracy: 20}},
+				{{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 470001000, Accuracy: 5}},
+			},
+			want: []Location{
+				{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 470000000, Accuracy: 20},
+			},
+		}, {
+			name: "Keeps same time outside of accuracy",
+			sources: [][]Location{
+				{{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 470000000, Accuracy: 5}},
+				{{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 470001000, Accuracy: 5}},
+			},
+			want: []Location{
+				{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 470000000, Accuracy: 5},
+				{Timestamp: "2020-01-01T10:00:00Z", LatitudeE7: 470001000, Accuracy: 5},
+			},
+		}, {
+			name: "Drops invalid timestamps",
+			sources: [][]Location{
+				{{Timestamp: "invalid"}},
+			},
+			want: []Location{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Merge(tc.sources...)
+			if diff := cmp.Diff(got, tc.want); diff != "" {
+				t.Errorf("Merge() = %v, want %v. Diff: %v", got, tc.want, diff)
+			}
+		})
+	}
+}
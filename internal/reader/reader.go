@@ -5,11 +5,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"path"
 	"strings"
 	"time"
+
+	"github.com/golang/geo/s2"
 )
 
+// isExport returns true if the given zip entry is a Records.json from takeout or a Timeline.json from an on-device
+// export, which may also be at the root of the zip file.
+func isExport(name string) bool {
+	return strings.HasSuffix(name, "/Records.json") || path.Base(name) == "Timeline.json"
+}
+
 func OpenFile(inputname string) (io.Reader, error) {
 	if strings.HasSuffix(inputname, ".zip") {
 		zfc, err := zip.OpenReader(inputname)
@@ -20,20 +30,57 @@ func OpenFile(inputname string) (io.Reader, error) {
 		// defer zfc.Close()
 
 		for _, f := range zfc.Reader.File {
-			if strings.HasSuffix(f.Name, "/Records.json") {
+			if isExport(f.Name) {
 				return f.Open()
 			}
 		}
-		return nil, fmt.Errorf("could not find Records.json inside zip file")
+		return nil, fmt.Errorf("could not find Records.json or Timeline.json inside zip file")
 	}
-	if strings.HasSuffix(inputname, ".json") {
-		return os.Open(inputname)
+	for _, ext := range []string{".json", ".gpx", ".kml", ".geojson"} {
+		if strings.HasSuffix(inputname, ext) {
+			return os.Open(inputname)
+		}
+	}
+	return nil, fmt.Errorf("only .zip, .json, .gpx, .kml and .geojson are supported")
+}
+
+// DecodeFunc reads locations from the given reader and calls fn for every location, ordered by time. The decoder is
+// chosen based on the extension of inputname, which is the name passed to OpenFile. Records.json is streamed as it is
+// ordered by time already, all other formats are decoded completely and sorted first, as e.g. tracks of a GPX file
+// may overlap.
+func DecodeFunc(inputname string, reader io.Reader, fn func(Location) error) error {
+	switch {
+	case strings.HasSuffix(inputname, ".gpx"):
+		return emitSorted(func(fn func(Location) error) error { return DecodeGpxFunc(reader, fn) }, fn)
+	case strings.HasSuffix(inputname, ".kml"):
+		return emitSorted(func(fn func(Location) error) error { return DecodeKmlFunc(reader, fn) }, fn)
+	case strings.HasSuffix(inputname, ".geojson"):
+		return emitSorted(func(fn func(Location) error) error { return DecodeGeoJsonFunc(reader, fn) }, fn)
+	default:
+		return DecodeJsonFunc(reader, fn)
 	}
-	return nil, fmt.Errorf("only .zip and .json are supported")
 }
 
 // DecodeJson attempts to read takeout-compatible JSON from the given reader.
 func DecodeJson(reader io.Reader) ([]Location, error) {
+	var locs []Location
+	err := DecodeJsonFunc(reader, func(loc Location) error {
+		locs = append(locs, loc)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return locs, nil
+}
+
+// DecodeJsonFunc reads takeout-compatible JSON from the given reader and calls fn for every location
+// in the order they appear in the file. Only a single location is held in memory at any time, which
+// allows processing exports that are too big to be decoded at once.
+// Both Records.json from takeout and Timeline.json from on-device exports are supported, see DecodeTimelineFunc
+// for details on the latter. As Timeline.json is not ordered by time, it is decoded completely and sorted first.
+// Decoding stops at the first error returned by fn, which is passed on to the caller.
+func DecodeJsonFunc(reader io.Reader, fn func(Location) error) error {
 	decoder := json.NewDecoder(reader)
 
 	// Read the following opening tokens:
@@ -41,22 +88,32 @@ func DecodeJson(reader io.Reader) ([]Location, error) {
 	// 2. "locations" field name,
 	// 3. the array value's opening bracket '['
 	for i := 0; i < 3; i++ {
-		_, err := decoder.Token()
+		t, err := decoder.Token()
 		if err != nil {
-			return nil, fmt.Errorf("decoding opening token: %v", err)
+			return fmt.Errorf("decoding opening token: %v", err)
+		}
+		if i == 1 && t != "locations" {
+			// Not a Records.json, attempt decoding as Timeline.json.
+			return emitSorted(func(fn func(Location) error) error {
+				if err := decodeTimelineField(decoder, t, fn); err != nil {
+					return err
+				}
+				return decodeTimeline(decoder, fn)
+			}, fn)
 		}
 	}
 
-	var locs []Location
 	for decoder.More() {
 		loc := Location{}
 		err := decoder.Decode(&loc)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if err := fn(loc); err != nil {
+			return err
 		}
-		locs = append(locs, loc)
 	}
-	return locs, nil
+	return nil
 }
 
 type Location struct {
@@ -66,28 +123,33 @@ type Location struct {
 	Accuracy         int          `json:"accuracy"`
 	Altitude         int          `json:"altitude,omitempty"`
 	VerticalAccuracy int          `json:"verticalAccuracy,omitempty"`
-	Activity         []activities `json:"activity,omitempty"`
+	Activity         []Activities `json:"activity,omitempty"`
 	Velocity         int          `json:"velocity,omitempty"`
 	Heading          int          `json:"heading,omitempty"`
 
+	// DeviceTag identifies the device that reported the location.
+	DeviceTag int `json:"deviceTag,omitempty"`
+
 	// Maybe useful?
 	FormFactor      string `json:"formFactor"` // PHONE
 	BatteryCharging bool   `json:"batteryCharging"`
 	Source          string `json:"source"`       // WIFI, GPS
 	PlatformType    string `json:"platformType"` // ANDROID
-	// locationMetadata has an array of wifi scans.
+
+	// LocationMetadata has an array of wifi scans.
+	LocationMetadata []LocationMetadata `json:"locationMetadata,omitempty"`
 }
 
 func (l Location) ParsedTimestamp() (time.Time, error) {
 	return time.Parse(time.RFC3339, l.Timestamp)
 }
 
-type activities struct {
-	Timestamp string     `json:"timestamp"`
-	Activity  []activity `json:"activity"`
+// LatLng returns the coordinates of the location.
+func (l Location) LatLng() s2.LatLng {
+	return s2.LatLngFromDegrees(float64(l.LatitudeE7)/1e7, float64(l.LongitudeE7)/1e7)
 }
 
-type activity struct {
-	Type       string `json:"type"`
-	Confidence int    `json:"confidence"`
+// degreesToE7 converts degrees to the E7 representation used by Location.
+func degreesToE7(deg float64) int {
+	return int(math.Round(deg * 1e7))
 }
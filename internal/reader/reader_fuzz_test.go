@@ -18,6 +18,13 @@ func FuzzDecodeJson(f *testing.F) {
 		"source": "WIFI",
 		"timestamp": "2014-01-01T00:00:50.307Z"
 	  },
+	`, `
+	{
+		"semanticSegments": [{
+		"startTime": "2024-01-01T08:00:00.000+01:00",
+		"timelinePath": [{"point": "47.3769°, 8.5417°", "time": "2024-01-01T08:10:00.000+01:00"}]
+	  }],
+	  "rawSignals": [
 	`}
 	for _, tc := range testcases {
 		f.Add(tc)
@@ -0,0 +1,87 @@
+package reader
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const recordsJson = `
+{
+	"locations": [{
+		"latitudeE7": 123,
+		"longitudeE7": 456,
+		"accuracy": 19,
+		"source": "WIFI",
+		"timestamp": "2014-01-01T00:00:50.307Z"
+	}, {
+		"latitudeE7": 789,
+		"longitudeE7": 101,
+		"accuracy": 5,
+		"source": "GPS",
+		"timestamp": "2014-01-01T00:01:50.307Z"
+	}]
+}`
+
+func collect(t *testing.T, decode func(io.Reader, func(Location) error) error, input string) []Location {
+	t.Helper()
+	var res []Location
+	err := decode(strings.NewReader(input), func(l Location) error {
+		res = append(res, l)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("decode() = %v", err)
+	}
+	return res
+}
+
+func TestDecodeJsonFunc(t *testing.T) {
+	want := []Location{
+		{Timestamp: "2014-01-01T00:00:50.307Z", LatitudeE7: 123, LongitudeE7: 456, Accuracy: 19, Source: "WIFI"},
+		{Timestamp: "2014-01-01T00:01:50.307Z", LatitudeE7: 789, LongitudeE7: 101, Accuracy: 5, Source: "GPS"},
+	}
+	var got []Location
+	err := DecodeJsonFunc(strings.NewReader(recordsJson), func(loc Location) error {
+		got = append(got, loc)
+		return nil
+	})
+	if diff := cmp.Diff(got, want); err != nil || diff != "" {
+		t.Errorf("DecodeJsonFunc() = %v, %v, want %v. Diff: %v", got, err, want, diff)
+	}
+}
+
+func TestDecodeJsonFuncStopsOnError(t *testing.T) {
+	stop := errors.New("stop")
+	calls := 0
+	err := DecodeJsonFunc(strings.NewReader(recordsJson), func(loc Location) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("DecodeJsonFunc() = %v after %d calls, want %v after 1 call", err, calls, stop)
+	}
+}
+
+func TestIsExport(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		want bool
+	}{
+		{name: "Takeout/Location History/Records.json", want: true},
+		{name: "Records.json", want: false},
+		{name: "Timeline.json", want: true},
+		{name: "export/Timeline.json", want: true},
+		{name: "Takeout/Location History/Semantic Location History/OldTimeline.json", want: false},
+		{name: "MyTimeline.json", want: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isExport(tc.name); got != tc.want {
+				t.Errorf("isExport(%q) = %t, want %t", tc.name, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package reader
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SemanticHistory holds the content of one or more files from "Semantic Location History" in takeout.
+type SemanticHistory struct {
+	Visits   []PlaceVisit
+	Segments []ActivitySegment
+}
+
+// PlaceVisit is a stay at a single place as recognized by Google.
+type PlaceVisit struct {
+	Location        Place    `json:"location"`
+	Duration        Duration `json:"duration"`
+	PlaceConfidence string   `json:"placeConfidence"` // HIGH_CONFIDENCE, MEDIUM_CONFIDENCE, ...
+	VisitConfidence int      `json:"visitConfidence"`
+}
+
+// Place is the location of a PlaceVisit.
+type Place struct {
+	LatitudeE7         int     `json:"latitudeE7"`
+	LongitudeE7        int     `json:"longitudeE7"`
+	PlaceID            string  `json:"placeId"`
+	Address            string  `json:"address"`
+	Name               string  `json:"name"`
+	SemanticType       string  `json:"semanticType"` // TYPE_HOME, TYPE_WORK, ...
+	LocationConfidence float64 `json:"locationConfidence"`
+}
+
+// ActivitySegment is a movement between two places.
+type ActivitySegment struct {
+	StartLocation Waypoint `json:"startLocation"`
+	EndLocation   Waypoint `json:"endLocation"`
+	Duration      Duration `json:"duration"`
+	// Distance in meters.
+	Distance     int    `json:"distance"`
+	ActivityType string `json:"activityType"` // WALKING, IN_PASSENGER_VEHICLE, ...
+	Confidence   string `json:"confidence"`   // HIGH, MEDIUM, LOW
+	WaypointPath struct {
+		Waypoints []Waypoint `json:"waypoints"`
+	} `json:"waypointPath"`
+}
+
+// Waypoint is a point without timestamp. Depending on where it's used, coordinates are either stored as
+// latitudeE7/longitudeE7 or latE7/lngE7.
+type Waypoint struct {
+	LatitudeE7  int `json:"latitudeE7"`
+	LongitudeE7 int `json:"longitudeE7"`
+	LatE7       int `json:"latE7"`
+	LngE7       int `json:"lngE7"`
+}
+
+// Coordinates returns the coordinates of the waypoint in E7 representation, regardless of which fields were set.
+func (w Waypoint) Coordinates() (latE7, lngE7 int) {
+	if w.LatE7 != 0 || w.LngE7 != 0 {
+		return w.LatE7, w.LngE7
+	}
+	return w.LatitudeE7, w.LongitudeE7
+}
+
+// Duration is the time span of a visit or segment. Older exports use timestamps in milliseconds since epoch,
+// newer ones RFC3339 timestamps.
+type Duration struct {
+	StartTimestamp   string `json:"startTimestamp,omitempty"`
+	EndTimestamp     string `json:"endTimestamp,omitempty"`
+	StartTimestampMs string `json:"startTimestampMs,omitempty"`
+	EndTimestampMs   string `json:"endTimestampMs,omitempty"`
+}
+
+// Start returns the parsed start of the duration.
+func (d Duration) Start() (time.Time, error) {
+	return parseSemanticTimestamp(d.StartTimestamp, d.StartTimestampMs)
+}
+
+// End returns the parsed end of the duration.
+func (d Duration) End() (time.Time, error) {
+	return parseSemanticTimestamp(d.EndTimestamp, d.EndTimestampMs)
+}
+
+func parseSemanticTimestamp(ts, ms string) (time.Time, error) {
+	if ts != "" {
+		return time.Parse(time.RFC3339, ts)
+	}
+	v, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed parsing timestamp from %q: %w", ms, err)
+	}
+	return time.UnixMilli(v).UTC(), nil
+}
+
+type timelineObject struct {
+	PlaceVisit      *PlaceVisit      `json:"placeVisit,omitempty"`
+	ActivitySegment *ActivitySegment `json:"activitySegment,omitempty"`
+}
+
+// OpenSemanticFiles reads all monthly files of "Semantic Location History" inside a takeout .zip,
+// or a single monthly .json file. Visits and segments are ordered by their start.
+func OpenSemanticFiles(inputname string) (SemanticHistory, error) {
+	if strings.HasSuffix(inputname, ".json") {
+		f, err := os.Open(inputname)
+		if err != nil {
+			return SemanticHistory{}, err
+		}
+		defer f.Close()
+		return DecodeSemanticJson(f)
+	}
+	if !strings.HasSuffix(inputname, ".zip") {
+		return SemanticHistory{}, fmt.Errorf("only .zip and .json are supported")
+	}
+	zfc, err := zip.OpenReader(inputname)
+	if err != nil {
+		return SemanticHistory{}, err
+	}
+	defer zfc.Close()
+
+	var res SemanticHistory
+	found := false
+	for _, f := range zfc.Reader.File {
+		if !strings.Contains(f.Name, "Semantic Location History/") || !strings.HasSuffix(f.Name, ".json") {
+			continue
+		}
+		found = true
+		r, err := f.Open()
+		if err != nil {
+			return SemanticHistory{}, err
+		}
+		h, err := DecodeSemanticJson(r)
+		r.Close()
+		if err != nil {
+			return SemanticHistory{}, fmt.Errorf("decoding %s: %w", f.Name, err)
+		}
+		res.Visits = append(res.Visits, h.Visits...)
+		res.Segments = append(res.Segments, h.Segments...)
+	}
+	if !found {
+		return SemanticHistory{}, fmt.Errorf("could not find Semantic Location History inside zip file")
+	}
+	res.sort()
+	return res, nil
+}
+
+// DecodeSemanticJson reads a single monthly file of "Semantic Location History", e.g. 2019_JANUARY.json.
+func DecodeSemanticJson(reader io.Reader) (SemanticHistory, error) {
+	var file struct {
+		TimelineObjects []timelineObject `json:"timelineObjects"`
+	}
+	if err := json.NewDecoder(reader).Decode(&file); err != nil {
+		return SemanticHistory{}, err
+	}
+	var res SemanticHistory
+	for _, o := range file.TimelineObjects {
+		if o.PlaceVisit != nil {
+			res.Visits = append(res.Visits, *o.PlaceVisit)
+		}
+		if o.ActivitySegment != nil {
+			res.Segments = append(res.Segments, *o.ActivitySegment)
+		}
+	}
+	res.sort()
+	return res, nil
+}
+
+func (h SemanticHistory) sort() {
+	slices.SortStableFunc(h.Visits, func(a, b PlaceVisit) int {
+		return compareStart(a.Duration, b.Duration)
+	})
+	slices.SortStableFunc(h.Segments, func(a, b ActivitySegment) int {
+		return compareStart(a.Duration, b.Duration)
+	})
+}
+
+func compareStart(a, b Duration) int {
+	// Unparseable timestamps are treated as zero time and end up first.
+	ta, _ := a.Start()
+	tb, _ := b.Start()
+	return ta.Compare(tb)
+}
+
+// Locations converts visits and segments to locations, so they can be used in place of raw records.
+// Each visit results in a location at the place for its start and end, each segment in a location at its start
+// and end point. The result is ordered by time, entries with unparseable timestamps are skipped.
+func (h SemanticHistory) Locations() []Location {
+	res := make([]Location, 0, 2*(len(h.Visits)+len(h.Segments)))
+	add := func(d Duration, startLat, startLng, endLat, endLng int) {
+		start, errStart := d.Start()
+		end, errEnd := d.End()
+		if errStart != nil || errEnd != nil {
+			return
+		}
+		res = append(res,
+			Location{Timestamp: start.Format(time.RFC3339Nano), LatitudeE7: startLat, LongitudeE7: startLng},
+			Location{Timestamp: end.Format(time.RFC3339Nano), LatitudeE7: endLat, LongitudeE7: endLng},
+		)
+	}
+	for _, v := range h.Visits {
+		add(v.Duration, v.Location.LatitudeE7, v.Location.LongitudeE7, v.Location.LatitudeE7, v.Location.LongitudeE7)
+	}
+	for _, s := range h.Segments {
+		startLat, startLng := s.StartLocation.Coordinates()
+		endLat, endLng := s.EndLocation.Coordinates()
+		add(s.Duration, startLat, startLng, endLat, endLng)
+	}
+	slices.SortStableFunc(res, func(a, b Location) int {
+		ta, _ := a.ParsedTimestamp()
+		tb, _ := b.ParsedTimestamp()
+		return ta.Compare(tb)
+	})
+	return res
+}
@@ -0,0 +1,87 @@
+package reader
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const semanticJson = `
+{
+	"timelineObjects": [{
+		"placeVisit": {
+			"location": {
+				"latitudeE7": 469480000,
+				"longitudeE7": 74474000,
+				"placeId": "ChIJ",
+				"address": "Bundesplatz 3, 3005 Bern",
+				"name": "Bundeshaus",
+				"semanticType": "TYPE_WORK",
+				"locationConfidence": 93.5
+			},
+			"duration": {
+				"startTimestamp": "2019-01-02T09:00:00Z",
+				"endTimestamp": "2019-01-02T17:00:00Z"
+			},
+			"placeConfidence": "HIGH_CONFIDENCE",
+			"visitConfidence": 95
+		}
+	}, {
+		"activitySegment": {
+			"startLocation": {"latitudeE7": 473769000, "longitudeE7": 85417000},
+			"endLocation": {"latitudeE7": 469480000, "longitudeE7": 74474000},
+			"duration": {
+				"startTimestampMs": "1546412400000",
+				"endTimestampMs": "1546416000000"
+			},
+			"distance": 95000,
+			"activityType": "IN_TRAIN",
+			"confidence": "HIGH",
+			"waypointPath": {
+				"waypoints": [{"latE7": 471000000, "lngE7": 80000000}]
+			}
+		}
+	}]
+}`
+
+func TestDecodeSemanticJson(t *testing.T) {
+	got, err := DecodeSemanticJson(strings.NewReader(semanticJson))
+	if err != nil {
+		t.Fatalf("DecodeSemanticJson() = %v", err)
+	}
+	if len(got.Visits) != 1 || len(got.Segments) != 1 {
+		t.Fatalf("DecodeSemanticJson() = %d visits, %d segments, want 1 and 1", len(got.Visits), len(got.Segments))
+	}
+	if v := got.Visits[0]; v.Location.Name != "Bundeshaus" || v.Location.PlaceID != "ChIJ" || v.VisitConfidence != 95 {
+		t.Errorf("DecodeSemanticJson() visit = %+v", v)
+	}
+	s := got.Segments[0]
+	start, err := s.Duration.Start()
+	if want := time.Date(2019, 1, 2, 7, 0, 0, 0, time.UTC); err != nil || !start.Equal(want) {
+		t.Errorf("Duration.Start() = %v, %v, want %v", start, err, want)
+	}
+	if lat, lng := s.WaypointPath.Waypoints[0].Coordinates(); lat != 471000000 || lng != 80000000 {
+		t.Errorf("Waypoint.Coordinates() = %d, %d, want 471000000, 80000000", lat, lng)
+	}
+	if s.ActivityType != "IN_TRAIN" || s.Distance != 95000 {
+		t.Errorf("DecodeSemanticJson() segment = %+v", s)
+	}
+}
+
+func TestSemanticHistoryLocations(t *testing.T) {
+	h, err := DecodeSemanticJson(strings.NewReader(semanticJson))
+	if err != nil {
+		t.Fatalf("DecodeSemanticJson() = %v", err)
+	}
+	want := []Location{
+		{Timestamp: "2019-01-02T07:00:00Z", LatitudeE7: 473769000, LongitudeE7: 85417000},
+		{Timestamp: "2019-01-02T08:00:00Z", LatitudeE7: 469480000, LongitudeE7: 74474000},
+		{Timestamp: "2019-01-02T09:00:00Z", LatitudeE7: 469480000, LongitudeE7: 74474000},
+		{Timestamp: "2019-01-02T17:00:00Z", LatitudeE7: 469480000, LongitudeE7: 74474000},
+	}
+	if diff := cmp.Diff(h.Locations(), want); diff != "" {
+		t.Errorf("Locations() diff: %v", diff)
+	}
+}
@@ -0,0 +1,204 @@
+package reader
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// timelineSegment is an entry of "semanticSegments" in the on-device Timeline.json export.
+type timelineSegment struct {
+	StartTime    string `json:"startTime"`
+	EndTime      string `json:"endTime"`
+	TimelinePath []struct {
+		Point string `json:"point"`
+		Time  string `json:"time"`
+	} `json:"timelinePath,omitempty"`
+	Visit *struct {
+		TopCandidate struct {
+			PlaceID       string `json:"placeId"`
+			SemanticType  string `json:"semanticType"`
+			PlaceLocation struct {
+				LatLng string `json:"latLng"`
+			} `json:"placeLocation"`
+		} `json:"topCandidate"`
+	} `json:"visit,omitempty"`
+	Activity *struct {
+		Start struct {
+			LatLng string `json:"latLng"`
+		} `json:"start"`
+		End struct {
+			LatLng string `json:"latLng"`
+		} `json:"end"`
+		DistanceMeters float64 `json:"distanceMeters"`
+	} `json:"activity,omitempty"`
+}
+
+// timelineSignal is an entry of "rawSignals" in the on-device Timeline.json export.
+// Only positions are used, wifi scans and activity records are ignored.
+type timelineSignal struct {
+	Position *struct {
+		LatLng               string  `json:"LatLng"`
+		AccuracyMeters       float64 `json:"accuracyMeters"`
+		AltitudeMeters       float64 `json:"altitudeMeters"`
+		Source               string  `json:"source"`
+		Timestamp            string  `json:"timestamp"`
+		SpeedMetersPerSecond float64 `json:"speedMetersPerSecond"`
+	} `json:"position,omitempty"`
+}
+
+// DecodeTimelineFunc reads the on-device Timeline.json export from the given reader and calls fn for every
+// location found. Locations are extracted from
+// * points of the "timelinePath" of semantic segments,
+// * start and end of visits and activities of semantic segments,
+// * positions of "rawSignals".
+// The file is not ordered by time, e.g. "rawSignals" come after "semanticSegments". Therefore all locations are
+// decoded first and then passed on ordered by time. Points with coordinates that can not be parsed, e.g. empty ones,
+// are logged and skipped.
+func DecodeTimelineFunc(reader io.Reader, fn func(Location) error) error {
+	decoder := json.NewDecoder(reader)
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("decoding opening token: %v", err)
+	}
+	return emitSorted(func(fn func(Location) error) error {
+		return decodeTimeline(decoder, fn)
+	}, fn)
+}
+
+// decodeTimeline decodes the fields of the timeline object. The opening brace must already be consumed.
+func decodeTimeline(decoder *json.Decoder, fn func(Location) error) error {
+	for decoder.More() {
+		t, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("decoding field name: %v", err)
+		}
+		if err := decodeTimelineField(decoder, t, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeTimelineField decodes the value of the given top level field of the timeline object.
+func decodeTimelineField(decoder *json.Decoder, field json.Token, fn func(Location) error) error {
+	var err error
+	switch field {
+	case "semanticSegments":
+		err = decodeArray(decoder, func(segment timelineSegment) error {
+			return segment.locations(fn)
+		})
+	case "rawSignals":
+		err = decodeArray(decoder, func(signal timelineSignal) error {
+			return signal.locations(fn)
+		})
+	default:
+		// Skip over fields that don't contain locations, e.g. "userLocationProfile".
+		err = decoder.Decode(&json.RawMessage{})
+	}
+	if err != nil {
+		return fmt.Errorf("decoding %q: %w", field, err)
+	}
+	return nil
+}
+
+// decodeArray decodes a JSON array element by element and calls fn on every element.
+func decodeArray[T any](decoder *json.Decoder, fn func(T) error) error {
+	if t, err := decoder.Token(); err != nil {
+		return err
+	} else if t != json.Delim('[') {
+		return fmt.Errorf("expected array, got %v", t)
+	}
+	for decoder.More() {
+		var v T
+		if err := decoder.Decode(&v); err != nil {
+			return err
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	// Consume closing bracket.
+	_, err := decoder.Token()
+	return err
+}
+
+func (s timelineSegment) locations(fn func(Location) error) error {
+	for _, p := range s.TimelinePath {
+		if err := emitLatLng(p.Point, p.Time, fn); err != nil {
+			return err
+		}
+	}
+	if s.Visit != nil {
+		ll := s.Visit.TopCandidate.PlaceLocation.LatLng
+		if err := emitLatLng(ll, s.StartTime, fn); err != nil {
+			return err
+		}
+		if err := emitLatLng(ll, s.EndTime, fn); err != nil {
+			return err
+		}
+	}
+	if s.Activity != nil {
+		if err := emitLatLng(s.Activity.Start.LatLng, s.StartTime, fn); err != nil {
+			return err
+		}
+		if err := emitLatLng(s.Activity.End.LatLng, s.EndTime, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s timelineSignal) locations(fn func(Location) error) error {
+	p := s.Position
+	if p == nil {
+		return nil
+	}
+	lat, lng, err := parseLatLng(p.LatLng)
+	if err != nil {
+		log.Default().Printf("Skipping position at %s: %v", p.Timestamp, err)
+		return nil
+	}
+	return fn(Location{
+		Timestamp:   p.Timestamp,
+		LatitudeE7:  lat,
+		LongitudeE7: lng,
+		Accuracy:    int(math.Round(p.AccuracyMeters)),
+		Altitude:    int(math.Round(p.AltitudeMeters)),
+		Velocity:    int(math.Round(p.SpeedMetersPerSecond)),
+		Source:      p.Source,
+	})
+}
+
+// emitLatLng calls fn with the location at the given coordinates, or logs and skips it if they can't be parsed.
+func emitLatLng(latLng, timestamp string, fn func(Location) error) error {
+	lat, lng, err := parseLatLng(latLng)
+	if err != nil {
+		log.Default().Printf("Skipping point at %s: %v", timestamp, err)
+		return nil
+	}
+	return fn(Location{Timestamp: timestamp, LatitudeE7: lat, LongitudeE7: lng})
+}
+
+// parseLatLng parses coordinates as found in Timeline.json, e.g. "47.3769°, 8.5417°" or "geo:47.3769,8.5417",
+// and returns them in E7 representation.
+func parseLatLng(s string) (latE7, lngE7 int, err error) {
+	s = strings.TrimPrefix(s, "geo:")
+	s = strings.ReplaceAll(s, "°", "")
+	lat, lng, found := strings.Cut(s, ",")
+	if !found {
+		return 0, 0, fmt.Errorf("coordinates %q do not contain a comma", s)
+	}
+	latF, err := strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error parsing lat from %q: %w", s, err)
+	}
+	lngF, err := strconv.ParseFloat(strings.TrimSpace(lng), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error parsing lng from %q: %w", s, err)
+	}
+	return degreesToE7(latF), degreesToE7(lngF), nil
+}
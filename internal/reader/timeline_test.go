@@ -0,0 +1,232 @@
+package reader
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const timelineJson = `
+{
+	"semanticSegments": [{
+		"startTime": "2024-01-01T08:00:00.000+01:00",
+		"endTime": "2024-01-01T09:00:00.000+01:00",
+		"timelinePath": [{
+			"point": "47.3769000°, 8.5417000°",
+			"time": "2024-01-01T08:10:00.000+01:00"
+		}]
+	}, {
+		"startTime": "2024-01-01T09:00:00.000+01:00",
+		"endTime": "2024-01-01T17:00:00.000+01:00",
+		"visit": {
+			"hierarchyLevel": 0,
+			"probability": 0.9,
+			"topCandidate": {
+				"placeId": "ChIJ",
+				"semanticType": "WORK",
+				"probability": 0.8,
+				"placeLocation": {"latLng": "46.9480000°, 7.4474000°"}
+			}
+		}
+	}, {
+		"startTime": "2024-01-01T17:00:00.000+01:00",
+		"endTime": "2024-01-01T18:00:00.000+01:00",
+		"activity": {
+			"start": {"latLng": "46.9480000°, 7.4474000°"},
+			"end": {"latLng": "47.3769000°, 8.5417000°"},
+			"distanceMeters": 95000.0,
+			"topCandidate": {"type": "IN_PASSENGER_VEHICLE", "probability": 0.7}
+		}
+	}],
+	"rawSignals": [{
+		"position": {
+			"LatLng": "47.3769000°, 8.5417000°",
+			"accuracyMeters": 12,
+			"altitudeMeters": 408.2,
+			"source": "WIFI",
+			"timestamp": "2024-01-01T19:00:00.000+01:00",
+			"speedMetersPerSecond": 0.0
+		}
+	}, {
+		"activityRecord": {
+			"probableActivities": [{"type": "STILL", "confidence": 0.9}],
+			"timestamp": "2024-01-01T19:00:00.000+01:00"
+		}
+	}],
+	"userLocationProfile": {"frequentPlaces": []}
+}`
+
+func TestDecodeTimeline(t *testing.T) {
+	want := []Location{
+		{Timestamp: "2024-01-01T08:10:00.000+01:00", LatitudeE7: 473769000, LongitudeE7: 85417000},
+		{Timestamp: "2024-01-01T09:00:00.000+01:00", LatitudeE7: 469480000, LongitudeE7: 74474000},
+		{Timestamp: "2024-01-01T17:00:00.000+01:00", LatitudeE7: 469480000, LongitudeE7: 74474000},
+		{Timestamp: "2024-01-01T17:00:00.000+01:00", LatitudeE7: 469480000, LongitudeE7: 74474000},
+		{Timestamp: "2024-01-01T18:00:00.000+01:00", LatitudeE7: 473769000, LongitudeE7: 85417000},
+		{Timestamp: "2024-01-01T19:00:00.000+01:00", LatitudeE7: 473769000, LongitudeE7: 85417000, Accuracy: 12, Altitude: 408, Source: "WIFI"},
+	}
+	for _, tc := range []struct {
+		name   string
+		decode func(string) ([]Location, error)
+	}{
+		{
+			name: "DecodeTimelineFunc",
+			decode: func(input string) ([]Location, error) {
+				var res []Location
+				err := DecodeTimelineFunc(strings.NewReader(input), func(l Location) error {
+					res = append(res, l)
+					return nil
+				})
+				return res, err
+			},
+		}, {
+			name: "DecodeJson detects format",
+			decode: func(input string) ([]Location, error) {
+				return DecodeJson(strings.NewReader(input))
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.decode(timelineJson)
+			if diff := cmp.Diff(got, want); err != nil || diff != "" {
+				t.Errorf("decode() = %v, %v, want %v. Diff: %v", got, err, want, diff)
+			}
+		})
+	}
+}
+
+func TestDecodeTimelineSkipsInvalidLatLng(t *testing.T) {
+	input := `
+{
+	"semanticSegments": [{
+		"startTime": "2024-01-01T08:00:00.000+01:00",
+		"endTime": "2024-01-01T09:00:00.000+01:00",
+		"timelinePath": [{
+			"point": "",
+			"time": "2024-01-01T08:10:00.000+01:00"
+		}, {
+			"point": "47.3769000°, 8.5417000°",
+			"time": "2024-01-01T08:50:00.000+01:00"
+		}]
+	}],
+	"rawSignals": [{
+		"position": {
+			"LatLng": "north, east",
+			"timestamp": "2024-01-01T19:00:00.000+01:00"
+		}
+	}, {
+		"position": {
+			"LatLng": "46.9480000°, 7.4474000°",
+			"timestamp": "2024-01-01T20:00:00.000+01:00"
+		}
+	}]
+}`
+	want := []Location{
+		{Timestamp: "2024-01-01T08:50:00.000+01:00", LatitudeE7: 473769000, LongitudeE7: 85417000},
+		{Timestamp: "2024-01-01T20:00:00.000+01:00", LatitudeE7: 469480000, LongitudeE7: 74474000},
+	}
+	var got []Location
+	err := DecodeTimelineFunc(strings.NewReader(input), func(l Location) error {
+		got = append(got, l)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("DecodeTimelineFunc() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseLatLng(t *testing.T) {
+	for _, tc := range []struct {
+		input   string
+		wantLat int
+		wantLng int
+		wantErr bool
+	}{
+		{input: "47.3769°, 8.5417°", wantLat: 473769000, wantLng: 85417000},
+		{input: "geo:-33.8688,151.2093", wantLat: -338688000, wantLng: 1512093000},
+		{input: "47.3769°", wantErr: true},
+		{input: "north, east", wantErr: true},
+	} {
+		t.Run(tc.input, func(t *testing.T) {
+			lat, lng, err := parseLatLng(tc.input)
+			if (err != nil) != tc.wantErr || lat != tc.wantLat || lng != tc.wantLng {
+				t.Errorf("parseLatLng(%q) = %d, %d, %v, want %d, %d, error: %t", tc.input, lat, lng, err, tc.wantLat, tc.wantLng, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecodeTimelineOrdersByTime(t *testing.T) {
+	// Raw signals are recorded before the semantic segments are computed from them, so they often cover earlier times.
+	input := `
+{
+	"semanticSegments": [{
+		"startTime": "2024-01-02T08:00:00.000+01:00",
+		"endTime": "2024-01-02T09:00:00.000+01:00",
+		"timelinePath": [{
+			"point": "46.9480000°, 7.4474000°",
+			"time": "2024-01-02T08:10:00.000+01:00"
+		}, {
+			"point": "47.3769000°, 8.5417000°",
+			"time": "2024-01-02T08:50:00.000+01:00"
+		}]
+	}],
+	"rawSignals": [{
+		"position": {
+			"LatLng": "47.3769000°, 8.5417000°",
+			"timestamp": "2024-01-02T08:30:00.000+01:00"
+		}
+	}, {
+		"position": {
+			"LatLng": "46.9480000°, 7.4474000°",
+			"timestamp": "2024-01-01T19:00:00.000+01:00"
+		}
+	}, {
+		"position": {
+			"LatLng": "46.9480000°, 7.4474000°",
+			"timestamp": "yesterday"
+		}
+	}]
+}`
+	want := []string{
+		"2024-01-01T19:00:00.000+01:00",
+		"2024-01-02T08:10:00.000+01:00",
+		"2024-01-02T08:30:00.000+01:00",
+		"2024-01-02T08:50:00.000+01:00",
+		"yesterday",
+	}
+	for _, tc := range []struct {
+		name   string
+		decode func(string, func(Location) error) error
+	}{
+		{
+			name: "DecodeTimelineFunc",
+			decode: func(input string, fn func(Location) error) error {
+				return DecodeTimelineFunc(strings.NewReader(input), fn)
+			},
+		}, {
+			name: "DecodeFunc",
+			decode: func(input string, fn func(Location) error) error {
+				return DecodeFunc("Timeline.json", strings.NewReader(input), fn)
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			err := tc.decode(input, func(l Location) error {
+				got = append(got, l.Timestamp)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("timestamps diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
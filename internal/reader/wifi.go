@@ -0,0 +1,73 @@
+package reader
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocationMetadata holds additional measurements recorded together with a location.
+type LocationMetadata struct {
+	Timestamp   string   `json:"timestamp,omitempty"`
+	TimestampMs string   `json:"timestampMs,omitempty"`
+	WifiScan    WifiScan `json:"wifiScan"`
+}
+
+// ParsedTimestamp returns the time of the measurement. Older exports use milliseconds since epoch.
+func (m LocationMetadata) ParsedTimestamp() (time.Time, error) {
+	return parseSemanticTimestamp(m.Timestamp, m.TimestampMs)
+}
+
+// WifiScan is a single scan for wifi access points.
+type WifiScan struct {
+	AccessPoints []AccessPoint `json:"accessPoints"`
+}
+
+// AccessPoint is a wifi access point seen during a scan.
+type AccessPoint struct {
+	MAC MAC `json:"mac"`
+	// Strength of the signal in dBm.
+	Strength     int  `json:"strength"`
+	FrequencyMhz int  `json:"frequencyMhz"`
+	IsConnected  bool `json:"isConnected,omitempty"`
+}
+
+// MAC is the hardware address of an access point. Takeout stores it as a decimal number, either quoted or not.
+type MAC uint64
+
+func (m *MAC) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed parsing mac from %s: %w", data, err)
+	}
+	*m = MAC(v)
+	return nil
+}
+
+func (m MAC) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strconv.FormatUint(uint64(m), 10))
+}
+
+// String formats the address in the usual notation, e.g. 00:1a:2b:3c:4d:5e.
+func (m MAC) String() string {
+	var b strings.Builder
+	for i := 5; i >= 0; i-- {
+		fmt.Fprintf(&b, "%02x", byte(m>>(8*i)))
+		if i > 0 {
+			b.WriteByte(':')
+		}
+	}
+	return b.String()
+}
+
+// AccessPoints returns the access points of all wifi scans of the location.
+func (l Location) AccessPoints() []AccessPoint {
+	var res []AccessPoint
+	for _, m := range l.LocationMetadata {
+		res = append(res, m.WifiScan.AccessPoints...)
+	}
+	return res
+}
@@ -0,0 +1,56 @@
+package reader
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecodeLocationMetadata(t *testing.T) {
+	input := `
+{
+	"locations": [{
+		"latitudeE7": 123,
+		"longitudeE7": 456,
+		"timestamp": "2014-01-01T00:00:50.307Z",
+		"locationMetadata": [{
+			"timestampMs": "1388534450307",
+			"wifiScan": {
+				"accessPoints": [{
+					"mac": "112394521950",
+					"strength": -62,
+					"frequencyMhz": 2437,
+					"isConnected": true
+				}, {
+					"mac": 1,
+					"strength": -90,
+					"frequencyMhz": 5180
+				}]
+			}
+		}]
+	}]
+}`
+	locs, err := DecodeJson(strings.NewReader(input))
+	if err != nil || len(locs) != 1 {
+		t.Fatalf("DecodeJson() = %v, %v, want one location", locs, err)
+	}
+	want := []AccessPoint{
+		{MAC: 112394521950, Strength: -62, FrequencyMhz: 2437, IsConnected: true},
+		{MAC: 1, Strength: -90, FrequencyMhz: 5180},
+	}
+	if diff := cmp.Diff(locs[0].AccessPoints(), want); diff != "" {
+		t.Errorf("AccessPoints() = %v, want %v. Diff: %v", locs[0].AccessPoints(), want, diff)
+	}
+	ts, err := locs[0].LocationMetadata[0].ParsedTimestamp()
+	if want := time.Date(2014, 1, 1, 0, 0, 50, 307000000, time.UTC); err != nil || !ts.Equal(want) {
+		t.Errorf("ParsedTimestamp() = %v, %v, want %v", ts, err, want)
+	}
+}
+
+func TestMACString(t *testing.T) {
+	if got, want := MAC(0x001a2b3c4d5e).String(), "00:1a:2b:3c:4d:5e"; got != want {
+		t.Errorf("MAC.String() = %q, want %q", got, want)
+	}
+}
@@ -7,14 +7,39 @@ import (
 
 	"github.com/go-echarts/go-echarts/v2/charts"
 	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/google/go-units/unit"
 	"github.com/panmari/locationhistory/internal/processor"
 )
 
-func generateBarItems(items []processor.DistanceByTimeBucket) []opts.BarData {
-	res := make([]opts.BarData, 0, len(items))
-	for _, i := range items {
-		v := math.Max(math.Log(i.Distance.Kilometers()*1000)-3.3, 0)
-		res = append(res, opts.BarData{Value: v})
+// barSeries holds the bars of all buckets measured to a single anchor. Bars of other anchors are left empty.
+type barSeries struct {
+	anchor string
+	data   []opts.BarData
+}
+
+// logDistance maps the distance to the height of its bar on a log scale, so both short and long distances are visible.
+func logDistance(d unit.Length) float64 {
+	return math.Max(math.Log(d.Kilometers()*1000)-3.3, 0)
+}
+
+// generateBarSeries creates one series per anchor, in order of first appearance, so bars can be colored by anchor.
+// Distances are mapped to the height of their bars using the given function.
+func generateBarSeries(items []processor.DistanceByTimeBucket, value func(unit.Length) float64) []barSeries {
+	var res []barSeries
+	index := make(map[string]int)
+	for j, d := range items {
+		i, ok := index[d.Anchor]
+		if !ok {
+			data := make([]opts.BarData, len(items))
+			for k := range data {
+				// Echarts skips data with value "-".
+				data[k] = opts.BarData{Value: "-"}
+			}
+			i = len(res)
+			index[d.Anchor] = i
+			res = append(res, barSeries{anchor: d.Anchor, data: data})
+		}
+		res[i].data[j] = opts.BarData{Value: value(d.Distance)}
 	}
 	return res
 }
@@ -22,15 +47,41 @@ func generateBarItems(items []processor.DistanceByTimeBucket) []opts.BarData {
 func generateXAxis(items []processor.DistanceByTimeBucket) []string {
 	res := make([]string, 0, len(items))
 	for _, i := range items {
-		res = append(res, i.Bucket.Format(time.DateOnly))
+		res = append(res, bucketName(i))
 	}
 	return res
 }
 
+// bucketName returns the date of the bucket, followed by the name of its anchor if it has one.
+func bucketName(d processor.DistanceByTimeBucket) string {
+	if d.Anchor == "" {
+		return d.Bucket.Format(time.DateOnly)
+	}
+	return fmt.Sprintf("%s (%s)", d.Bucket.Format(time.DateOnly), d.Anchor)
+}
+
+// BarChart shows the distance of every bucket as bar. If distances were measured to several anchors, bars are
+// colored by anchor.
 func BarChart(items []processor.DistanceByTimeBucket) *charts.Bar {
+	return newBarChart(items, logDistance)
+}
+
+// PathLengthChart shows the distance traveled in every bucket as bar on a linear scale in kilometers, e.g. for the
+// buckets of a processor.PathLengthBucketer.
+func PathLengthChart(items []processor.DistanceByTimeBucket) *charts.Bar {
+	bar := newBarChart(items, unit.Length.Kilometers)
+	bar.SetGlobalOptions(
+		charts.WithYAxisOpts(opts.YAxis{Show: opts.Bool(true), Name: "km"}),
+	)
+	return bar
+}
+
+func newBarChart(items []processor.DistanceByTimeBucket, value func(unit.Length) float64) *charts.Bar {
+	series := generateBarSeries(items, value)
 	bar := charts.NewBar()
 	bar.SetGlobalOptions(
-		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(false)}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(len(series) > 1)}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Trigger: "axis"}),
 		charts.WithXAxisOpts(opts.XAxis{Show: opts.Bool(false),
 			AxisTick:  &opts.AxisTick{Show: opts.Bool(false)},
 			AxisLabel: &opts.AxisLabel{Show: opts.Bool(false)}}),
@@ -44,8 +95,20 @@ func BarChart(items []processor.DistanceByTimeBucket) *charts.Bar {
 	)
 
 	// Put data into instance
-	y := generateBarItems(items)
-	x := generateXAxis(items)
-	bar.SetXAxis(x).AddSeries("Distances", y)
+	bar.SetXAxis(generateXAxis(items))
+	for i, s := range series {
+		name := s.anchor
+		if name == "" {
+			name = "Distances"
+		}
+		var seriesOpts []charts.SeriesOpts
+		if len(series) > 1 {
+			// Stack series, so the bars of each bucket stay at the same position.
+			seriesOpts = append(seriesOpts,
+				charts.WithBarChartOpts(opts.BarChart{Stack: "distance"}),
+				charts.WithItemStyleOpts(opts.ItemStyle{Color: color(i, len(series))}))
+		}
+		bar.AddSeries(name, s.data, seriesOpts...)
+	}
 	return bar
 }
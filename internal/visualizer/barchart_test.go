@@ -0,0 +1,46 @@
+package visualizer
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-units/unit"
+	"github.com/panmari/locationhistory/internal/processor"
+)
+
+func TestGenerateXAxis(t *testing.T) {
+	items := []processor.DistanceByTimeBucket{{
+		Distance: 10 * unit.Kilometer,
+		Bucket:   time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC),
+	}, {
+		Distance: 5 * unit.Kilometer,
+		Bucket:   time.Date(2024, 5, 4, 0, 0, 0, 0, time.UTC),
+		Anchor:   "Home in Zurich",
+	}}
+	want := []string{"2024-05-03", "2024-05-04 (Home in Zurich)"}
+	if diff := cmp.Diff(want, generateXAxis(items)); diff != "" {
+		t.Errorf("generateXAxis() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateBarSeries(t *testing.T) {
+	day := time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)
+	items := []processor.DistanceByTimeBucket{
+		{Distance: 1 * unit.Kilometer, Bucket: day, Anchor: "home"},
+		{Distance: 1 * unit.Kilometer, Bucket: day.AddDate(0, 0, 1), Anchor: "office"},
+		{Distance: 0 * unit.Kilometer, Bucket: day.AddDate(0, 0, 2), Anchor: "home"},
+	}
+	want := []barSeries{{
+		anchor: "home",
+		data:   []opts.BarData{{Value: math.Log(1000) - 3.3}, {Value: "-"}, {Value: 0.0}},
+	}, {
+		anchor: "office",
+		data:   []opts.BarData{{Value: "-"}, {Value: math.Log(1000) - 3.3}, {Value: "-"}},
+	}}
+	if diff := cmp.Diff(want, generateBarSeries(items, logDistance), cmp.AllowUnexported(barSeries{})); diff != "" {
+		t.Errorf("generateBarSeries() diff (-want +got):\n%s", diff)
+	}
+}
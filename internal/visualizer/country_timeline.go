@@ -0,0 +1,71 @@
+package visualizer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/panmari/locationhistory/internal/processor"
+)
+
+// countrySeries holds the bars of all days spent in a single country.
+type countrySeries struct {
+	country string
+	data    []opts.BarData
+}
+
+// generateCountrySeries creates one series per country, in order of first appearance. Days spent in several
+// countries are split evenly between them, so every day has a bar of the same height.
+func generateCountrySeries(days []processor.CountryDay) []countrySeries {
+	var res []countrySeries
+	index := make(map[string]int)
+	for j, d := range days {
+		for _, country := range d.Countries {
+			i, ok := index[country]
+			if !ok {
+				data := make([]opts.BarData, len(days))
+				for k := range data {
+					// Echarts skips data with value "-".
+					data[k] = opts.BarData{Value: "-"}
+				}
+				i = len(res)
+				index[country] = i
+				res = append(res, countrySeries{country: country, data: data})
+			}
+			res[i].data[j] = opts.BarData{Value: 1 / float64(len(d.Countries))}
+		}
+	}
+	return res
+}
+
+// CountryTimeline shows a bar for every day, colored by the countries visited on that day.
+func CountryTimeline(days []processor.CountryDay) *charts.Bar {
+	xAxis := make([]string, 0, len(days))
+	for _, d := range days {
+		xAxis = append(xAxis, d.Day.Format(time.DateOnly))
+	}
+	series := generateCountrySeries(days)
+	bar := charts.NewBar()
+	bar.SetGlobalOptions(
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true), Top: "bottom"}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Trigger: "axis"}),
+		charts.WithXAxisOpts(opts.XAxis{Show: opts.Bool(false),
+			AxisTick:  &opts.AxisTick{Show: opts.Bool(false)},
+			AxisLabel: &opts.AxisLabel{Show: opts.Bool(false)}}),
+		charts.WithYAxisOpts(opts.YAxis{Show: opts.Bool(false),
+			AxisLabel:   &opts.AxisLabel{Show: opts.Bool(false)},
+			AxisPointer: &opts.AxisPointer{Show: opts.Bool(false)}}),
+		charts.WithInitializationOpts(opts.Initialization{
+			Width:  fmt.Sprintf("%dpx", 365*5+20),
+			Height: "200px",
+		}),
+	)
+	bar.SetXAxis(xAxis)
+	for i, s := range series {
+		bar.AddSeries(s.country, s.data,
+			charts.WithBarChartOpts(opts.BarChart{Stack: "country"}),
+			charts.WithItemStyleOpts(opts.ItemStyle{Color: color(i, len(series))}))
+	}
+	return bar
+}
@@ -0,0 +1,29 @@
+package visualizer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/google/go-cmp/cmp"
+	"github.com/panmari/locationhistory/internal/processor"
+)
+
+func TestGenerateCountrySeries(t *testing.T) {
+	day := time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)
+	days := []processor.CountryDay{
+		{Day: day, Countries: []string{"CH"}},
+		{Day: day.AddDate(0, 0, 1), Countries: []string{"CH", "DE"}},
+		{Day: day.AddDate(0, 0, 2), Countries: []string{"DE"}},
+	}
+	want := []countrySeries{{
+		country: "CH",
+		data:    []opts.BarData{{Value: 1.0}, {Value: 0.5}, {Value: "-"}},
+	}, {
+		country: "DE",
+		data:    []opts.BarData{{Value: "-"}, {Value: 0.5}, {Value: 1.0}},
+	}}
+	if diff := cmp.Diff(want, generateCountrySeries(days), cmp.AllowUnexported(countrySeries{})); diff != "" {
+		t.Errorf("generateCountrySeries() diff (-want +got):\n%s", diff)
+	}
+}
@@ -12,9 +12,9 @@ import (
 	"github.com/panmari/locationhistory/internal/processor"
 )
 
+// Options for daily charts. Time zones are taken from the buckets, see processor.DistanceByTimeBucket.
 type Options struct {
-	Title    string
-	TimeZone *time.Location
+	Title string
 }
 
 // generateRadarItems creates daily radar items from the given slice of daily vectors.
@@ -17,6 +17,7 @@ type dailyVector struct {
 // distances with a measurement for each hour, grouped by day.
 // * If a time range does not have a value, the last available data point is used. This also applies for dates without coverage.
 // * For the last day, distances without values have 0
+// * Days are split at midnight in the time zone of the items' buckets.
 // Assumes that items are ordered by time ascendingly.
 func computeDailyVectors(items []processor.DistanceByTimeBucket) []dailyVector {
 	if len(items) == 0 {
@@ -25,9 +26,12 @@ func computeDailyVectors(items []processor.DistanceByTimeBucket) []dailyVector {
 	res := make([]dailyVector, 0)
 	dayCount := 0
 	i := 0
-	// TODO(panmari): Make use of options.TimeZone for shifting the start of the day before rounding.
-	day := items[i].Bucket.Round(time.Hour * 24)
+	day := items[i].Bucket
 	for {
+		// Days start at midnight in the time zone of the current item, so moves to another time zone are
+		// accounted for.
+		loc := items[i].Bucket.Location()
+		day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
 		distances := [24]float64{}
 		t := day
 		for j := range distances {
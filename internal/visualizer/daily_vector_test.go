@@ -54,6 +54,42 @@ func TestComputeDailyVectors(t *testing.T) {
 	}
 }
 
+func TestComputeDailyVectorsMoveAcrossTimeZones(t *testing.T) {
+	zurich, err := time.LoadLocation("Europe/Zurich")
+	if err != nil {
+		t.Fatal(err)
+	}
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := []processor.DistanceByTimeBucket{
+		{Distance: 1 * unit.Kilometer, Bucket: time.Date(2016, 2, 1, 10, 0, 0, 0, zurich)},
+		{Distance: 2 * unit.Kilometer, Bucket: time.Date(2016, 2, 2, 10, 0, 0, 0, la)},
+		{Distance: 3 * unit.Kilometer, Bucket: time.Date(2016, 2, 3, 10, 0, 0, 0, la)},
+	}
+	got := computeDailyVectors(items)
+	wantDays := []time.Time{
+		time.Date(2016, 2, 1, 0, 0, 0, 0, zurich),
+		time.Date(2016, 2, 2, 0, 0, 0, 0, zurich),
+		time.Date(2016, 2, 3, 0, 0, 0, 0, la),
+	}
+	var gotDays []time.Time
+	for _, dv := range got {
+		gotDays = append(gotDays, dv.Day)
+	}
+	if diff := cmp.Diff(wantDays, gotDays); diff != "" {
+		t.Fatalf("computeDailyVectors() days diff (-want +got):\n%s", diff)
+	}
+	// The second item is at 19:00 in Zurich.
+	if got[1].Values[18] != 1 || got[1].Values[19] != 2 {
+		t.Errorf("computeDailyVectors()[1] = %v, want switch at 19:00", got[1].Values)
+	}
+	if got[2].Values[9] != 2 || got[2].Values[10] != 3 {
+		t.Errorf("computeDailyVectors()[2] = %v, want switch at 10:00", got[2].Values)
+	}
+}
+
 func TestCosineSimilarity(t *testing.T) {
 	for _, tc := range []struct {
 		name string
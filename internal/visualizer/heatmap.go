@@ -14,6 +14,8 @@ var (
 	weekDays = [...]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
 )
 
+// transformToHeatMapData places every bucket in a week column and weekday row. Weekdays are taken in the time zone
+// of the bucket, i.e. the time zone of its anchor.
 func transformToHeatMapData(items []processor.DistanceByTimeBucket) []opts.HeatMapData {
 	if len(items) == 0 {
 		return nil
@@ -31,7 +33,7 @@ func transformToHeatMapData(items []processor.DistanceByTimeBucket) []opts.HeatM
 			}
 		}
 		v := math.Log(dbb.Distance.Kilometers())
-		res = append(res, opts.HeatMapData{Name: t.Format(time.DateOnly), Value: [3]interface{}{week, y, v}})
+		res = append(res, opts.HeatMapData{Name: bucketName(dbb), Value: [3]interface{}{week, y, v}})
 		// TODO(panmari): This assumes the data is complete and does not have gaps.
 		if t.Weekday() == time.Saturday {
 			week++
@@ -0,0 +1,46 @@
+package visualizer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/panmari/locationhistory/internal/processor"
+)
+
+// generateAllowanceData returns the remaining allowance of every day. Days spent in the Schengen area are marked.
+func generateAllowanceData(days []processor.SchengenDay) []opts.LineData {
+	res := make([]opts.LineData, 0, len(days))
+	for _, d := range days {
+		symbol := "none"
+		if d.Present {
+			symbol = "circle"
+		}
+		res = append(res, opts.LineData{Value: d.Remaining(), Symbol: symbol})
+	}
+	return res
+}
+
+// SchengenChart shows the remaining days of the 90/180 allowance over time.
+func SchengenChart(days []processor.SchengenDay) *charts.Line {
+	xAxis := make([]string, 0, len(days))
+	for _, d := range days {
+		xAxis = append(xAxis, d.Day.Format(time.DateOnly))
+	}
+	line := charts.NewLine()
+	line.SetGlobalOptions(
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Trigger: "axis"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: "Remaining days", Max: processor.SchengenMaxDays}),
+		charts.WithInitializationOpts(opts.Initialization{
+			Width:  fmt.Sprintf("%dpx", 365*5+20),
+			Height: "400px",
+		}),
+	)
+	line.SetXAxis(xAxis)
+	line.AddSeries("Remaining days", generateAllowanceData(days),
+		charts.WithLineChartOpts(opts.LineChart{Step: "end"}),
+		charts.WithAreaStyleOpts(opts.AreaStyle{Opacity: opts.Float(0.3)}),
+		charts.WithMarkLineNameYAxisItemOpts(opts.MarkLineNameYAxisItem{Name: "Limit", YAxis: 0}))
+	return line
+}
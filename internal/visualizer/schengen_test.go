@@ -0,0 +1,23 @@
+package visualizer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/google/go-cmp/cmp"
+	"github.com/panmari/locationhistory/internal/processor"
+)
+
+func TestGenerateAllowanceData(t *testing.T) {
+	day := time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)
+	days := []processor.SchengenDay{
+		{Day: day, Present: true, Used: 1},
+		{Day: day.AddDate(0, 0, 1), Used: 1},
+		{Day: day.AddDate(0, 0, 2), Present: true, Used: 91},
+	}
+	want := []opts.LineData{{Value: 89, Symbol: "circle"}, {Value: 89, Symbol: "none"}, {Value: -1, Symbol: "circle"}}
+	if diff := cmp.Diff(want, generateAllowanceData(days)); diff != "" {
+		t.Errorf("generateAllowanceData() diff (-want +got):\n%s", diff)
+	}
+}
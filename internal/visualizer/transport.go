@@ -0,0 +1,92 @@
+package visualizer
+
+import (
+	"slices"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/panmari/locationhistory/internal/processor"
+)
+
+// modeSeries holds the bars of a single mode of transport.
+type modeSeries struct {
+	mode processor.Mode
+	data []opts.BarData
+}
+
+// generateModeSeries creates one series per mode of transport in the order of processor.Modes, with one bar per
+// bucket. Modes not used at all are skipped.
+func generateModeSeries(items []processor.ModeByTimeBucket, value func(processor.ModeByTimeBucket) float64) ([]string, []modeSeries) {
+	var xAxis []string
+	index := make(map[string]int)
+	for _, m := range items {
+		name := m.Bucket.Format(time.DateOnly)
+		if _, ok := index[name]; !ok {
+			index[name] = len(xAxis)
+			xAxis = append(xAxis, name)
+		}
+	}
+	var res []modeSeries
+	for _, mode := range processor.Modes {
+		var data []opts.BarData
+		for _, m := range items {
+			if m.Mode != mode {
+				continue
+			}
+			if data == nil {
+				data = make([]opts.BarData, len(xAxis))
+				for k := range data {
+					// Echarts skips data with value "-".
+					data[k] = opts.BarData{Value: "-"}
+				}
+			}
+			data[index[m.Bucket.Format(time.DateOnly)]] = opts.BarData{Value: value(m)}
+		}
+		if data != nil {
+			res = append(res, modeSeries{mode: mode, data: data})
+		}
+	}
+	return xAxis, res
+}
+
+// ModeDistanceChart shows the distance traveled per mode of transport as stacked bars, e.g. for the monthly buckets
+// of processor.SumModesMonthly.
+func ModeDistanceChart(items []processor.ModeByTimeBucket) *charts.Bar {
+	return newModeChart(items, "km", func(m processor.ModeByTimeBucket) float64 { return m.Distance.Kilometers() })
+}
+
+// ModeDurationChart shows the time traveled per mode of transport as stacked bars.
+func ModeDurationChart(items []processor.ModeByTimeBucket) *charts.Bar {
+	return newModeChart(items, "h", func(m processor.ModeByTimeBucket) float64 { return m.Duration.Hours() })
+}
+
+// EmissionsChart shows the CO2 emitted per mode of transport as stacked bars, using the given emission factors. Modes
+// without emissions are skipped.
+func EmissionsChart(items []processor.ModeByTimeBucket, factors processor.EmissionFactors) *charts.Bar {
+	var emitting []processor.ModeByTimeBucket
+	for _, m := range items {
+		if factors[m.Mode] > 0 {
+			emitting = append(emitting, m)
+		}
+	}
+	return newModeChart(emitting, "kg CO2", factors.Kilograms)
+}
+
+func newModeChart(items []processor.ModeByTimeBucket, unitName string, value func(processor.ModeByTimeBucket) float64) *charts.Bar {
+	xAxis, series := generateModeSeries(items, value)
+	bar := charts.NewBar()
+	bar.SetGlobalOptions(
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true)}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Trigger: "axis"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: unitName}),
+	)
+	bar.SetXAxis(xAxis)
+	for _, s := range series {
+		// Colors are assigned by mode, so they are the same across charts.
+		bar.AddSeries(string(s.mode), s.data,
+			charts.WithBarChartOpts(opts.BarChart{Stack: "mode"}),
+			charts.WithItemStyleOpts(opts.ItemStyle{Color: color(slices.Index(processor.Modes, s.mode), len(processor.Modes))}))
+	}
+	return bar
+}
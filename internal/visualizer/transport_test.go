@@ -0,0 +1,49 @@
+package visualizer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-units/unit"
+	"github.com/panmari/locationhistory/internal/processor"
+)
+
+func TestGenerateModeSeries(t *testing.T) {
+	month := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	items := []processor.ModeByTimeBucket{
+		{Mode: processor.Walking, Distance: 2 * unit.Kilometer, Bucket: month},
+		{Mode: processor.Train, Distance: 100 * unit.Kilometer, Bucket: month},
+		{Mode: processor.Walking, Distance: 3 * unit.Kilometer, Bucket: month.AddDate(0, 1, 0)},
+	}
+	xAxis, series := generateModeSeries(items, func(m processor.ModeByTimeBucket) float64 { return m.Distance.Kilometers() })
+	if diff := cmp.Diff([]string{"2024-04-01", "2024-05-01"}, xAxis); diff != "" {
+		t.Errorf("generateModeSeries() x-axis diff (-want +got):\n%s", diff)
+	}
+	want := []modeSeries{{
+		mode: processor.Walking,
+		data: []opts.BarData{{Value: 2.0}, {Value: 3.0}},
+	}, {
+		mode: processor.Train,
+		data: []opts.BarData{{Value: 100.0}, {Value: "-"}},
+	}}
+	if diff := cmp.Diff(want, series, cmp.AllowUnexported(modeSeries{})); diff != "" {
+		t.Errorf("generateModeSeries() diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestEmissionsChart(t *testing.T) {
+	month := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	items := []processor.ModeByTimeBucket{
+		{Mode: processor.Walking, Distance: 2 * unit.Kilometer, Bucket: month},
+		{Mode: processor.Flight, Distance: 1000 * unit.Kilometer, Bucket: month},
+	}
+	bar := EmissionsChart(items, processor.EmissionFactors{processor.Walking: 0, processor.Flight: 150})
+	if len(bar.MultiSeries) != 1 || bar.MultiSeries[0].Name != string(processor.Flight) {
+		t.Fatalf("EmissionsChart() series = %+v, want a single flight series", bar.MultiSeries)
+	}
+	if diff := cmp.Diff([]opts.BarData{{Value: 150.0}}, bar.MultiSeries[0].Data); diff != "" {
+		t.Errorf("EmissionsChart() diff (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,37 @@
+package writer
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/panmari/locationhistory/internal/processor"
+)
+
+// WriteActivitiesFile writes the given time spent per activity and day to a file as CSV.
+func WriteActivitiesFile(filename string, durations []processor.ActivityDuration) error {
+	return writeFile(filename, func(w io.Writer) error {
+		return WriteActivitiesCsv(w, durations)
+	})
+}
+
+// WriteActivitiesCsv writes the given time spent per activity and day as CSV with a header row.
+func WriteActivitiesCsv(w io.Writer, durations []processor.ActivityDuration) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"date", "activity", "hours"}); err != nil {
+		return err
+	}
+	for _, d := range durations {
+		err := cw.Write([]string{
+			d.Bucket.Format(time.DateOnly),
+			d.Type,
+			strconv.FormatFloat(d.Duration.Hours(), 'f', 2, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,29 @@
+package writer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/panmari/locationhistory/internal/processor"
+)
+
+func TestWriteActivitiesCsv(t *testing.T) {
+	day := time.Date(2014, 4, 1, 0, 0, 0, 0, time.UTC)
+	durations := []processor.ActivityDuration{
+		{Type: "IN_VEHICLE", Duration: 40 * time.Minute, Bucket: day},
+		{Type: "STILL", Duration: 10 * time.Hour, Bucket: day},
+	}
+	var buf bytes.Buffer
+	if err := WriteActivitiesCsv(&buf, durations); err != nil {
+		t.Fatalf("WriteActivitiesCsv() = %v", err)
+	}
+	want := `date,activity,hours
+2014-04-01,IN_VEHICLE,0.67
+2014-04-01,STILL,10.00
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("WriteActivitiesCsv() diff: %v", diff)
+	}
+}
@@ -0,0 +1,33 @@
+package writer
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+// WriteCsv writes the given locations as CSV with a header row. Coordinates are written in degrees.
+func WriteCsv(w io.Writer, locations []reader.Location) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"timestamp", "latitude", "longitude", "accuracy", "altitude", "source"}); err != nil {
+		return err
+	}
+	for _, loc := range locations {
+		lat, lng := latLng(loc)
+		err := cw.Write([]string{
+			loc.Timestamp,
+			strconv.FormatFloat(lat, 'f', 7, 64),
+			strconv.FormatFloat(lng, 'f', 7, 64),
+			strconv.Itoa(loc.Accuracy),
+			strconv.Itoa(loc.Altitude),
+			loc.Source,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
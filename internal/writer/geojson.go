@@ -0,0 +1,54 @@
+package writer
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+type geoJsonFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJsonFeature `json:"features"`
+}
+
+type geoJsonFeature struct {
+	Type     string `json:"type"`
+	Geometry struct {
+		Type        string    `json:"type"`
+		Coordinates []float64 `json:"coordinates"`
+	} `json:"geometry"`
+	Properties geoJsonProperties `json:"properties"`
+}
+
+type geoJsonProperties struct {
+	Time     string `json:"time"`
+	Accuracy int    `json:"accuracy,omitempty"`
+	Source   string `json:"source,omitempty"`
+}
+
+// WriteGeoJson writes the given locations as a FeatureCollection with one Point feature per location.
+// The timestamp is stored in the "time" property.
+func WriteGeoJson(w io.Writer, locations []reader.Location) error {
+	doc := geoJsonFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]geoJsonFeature, 0, len(locations)),
+	}
+	for _, loc := range locations {
+		lat, lng := latLng(loc)
+		f := geoJsonFeature{
+			Type: "Feature",
+			Properties: geoJsonProperties{
+				Time:     loc.Timestamp,
+				Accuracy: loc.Accuracy,
+				Source:   loc.Source,
+			},
+		}
+		f.Geometry.Type = "Point"
+		f.Geometry.Coordinates = []float64{lng, lat}
+		doc.Features = append(doc.Features, f)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
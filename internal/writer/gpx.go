@@ -0,0 +1,51 @@
+package writer
+
+import (
+	"encoding/xml"
+	"io"
+
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+type gpx struct {
+	XMLName xml.Name   `xml:"gpx"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	Version string     `xml:"version,attr"`
+	Creator string     `xml:"creator,attr"`
+	Points  []gpxPoint `xml:"trk>trkseg>trkpt"`
+}
+
+type gpxPoint struct {
+	Lat  float64 `xml:"lat,attr"`
+	Lon  float64 `xml:"lon,attr"`
+	Ele  int     `xml:"ele,omitempty"`
+	Time string  `xml:"time"`
+}
+
+// WriteGpx writes the given locations as a single GPX track.
+func WriteGpx(w io.Writer, locations []reader.Location) error {
+	doc := gpx{
+		Xmlns:   "http://www.topografix.com/GPX/1/1",
+		Version: "1.1",
+		Creator: "github.com/panmari/locationhistory",
+		Points:  make([]gpxPoint, 0, len(locations)),
+	}
+	for _, loc := range locations {
+		lat, lng := latLng(loc)
+		doc.Points = append(doc.Points, gpxPoint{Lat: lat, Lon: lng, Ele: loc.Altitude, Time: loc.Timestamp})
+	}
+	return encodeXml(w, doc)
+}
+
+func encodeXml(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
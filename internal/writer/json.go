@@ -0,0 +1,22 @@
+package writer
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+// WriteJson writes the given locations in the layout of Records.json from takeout. In contrast to the other
+// formats, this keeps all fields, so the output can be read again by reader.DecodeJson without loss.
+func WriteJson(w io.Writer, locations []reader.Location) error {
+	if locations == nil {
+		locations = []reader.Location{}
+	}
+	doc := struct {
+		Locations []reader.Location `json:"locations"`
+	}{locations}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
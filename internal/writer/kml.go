@@ -0,0 +1,39 @@
+package writer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+type kml struct {
+	XMLName xml.Name `xml:"kml"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	XmlnsGx string   `xml:"xmlns:gx,attr"`
+	Track   kmlTrack `xml:"Document>Placemark>gx:Track"`
+}
+
+type kmlTrack struct {
+	When  []string `xml:"when"`
+	Coord []string `xml:"gx:coord"`
+}
+
+// WriteKml writes the given locations as a single gx:Track, which keeps the timestamp of every point.
+func WriteKml(w io.Writer, locations []reader.Location) error {
+	doc := kml{
+		Xmlns:   "http://www.opengis.net/kml/2.2",
+		XmlnsGx: "http://www.google.com/kml/ext/2.2",
+		Track: kmlTrack{
+			When:  make([]string, 0, len(locations)),
+			Coord: make([]string, 0, len(locations)),
+		},
+	}
+	for _, loc := range locations {
+		lat, lng := latLng(loc)
+		doc.Track.When = append(doc.Track.When, loc.Timestamp)
+		doc.Track.Coord = append(doc.Track.Coord, fmt.Sprintf("%g %g %d", lng, lat, loc.Altitude))
+	}
+	return encodeXml(w, doc)
+}
@@ -0,0 +1,107 @@
+package writer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/panmari/locationhistory/internal/processor"
+)
+
+// evidenceRecord is the representation of a processor.Evidence in exported files.
+type evidenceRecord struct {
+	Country string  `json:"country"`
+	Region  string  `json:"region"`
+	Time    string  `json:"time"`
+	Lat     float64 `json:"lat"`
+	Lng     float64 `json:"lng"`
+}
+
+// residencyDayRecord is the representation of a processor.ResidencyDay in exported files.
+type residencyDayRecord struct {
+	Day       string           `json:"day"`
+	Countries []string         `json:"countries"`
+	Evidence  []evidenceRecord `json:"evidence"`
+	// Carried marks days without locations, where the evidence is from an earlier day.
+	Carried bool `json:"carried,omitempty"`
+}
+
+func newResidencyDayRecord(d processor.ResidencyDay) residencyDayRecord {
+	r := residencyDayRecord{
+		Day:       d.Day.Format(time.DateOnly),
+		Countries: append([]string{}, d.Countries...),
+		Evidence:  make([]evidenceRecord, 0, len(d.Evidence)),
+		Carried:   d.Carried,
+	}
+	for _, e := range d.Evidence {
+		r.Evidence = append(r.Evidence, evidenceRecord{
+			Country: e.Country,
+			Region:  e.Region,
+			Time:    e.Time.Format(time.RFC3339),
+			Lat:     e.LatLng.Lat.Degrees(),
+			Lng:     e.LatLng.Lng.Degrees(),
+		})
+	}
+	return r
+}
+
+// WriteResidencyFile writes the given days as audit trail to a file, either as .csv or .json depending on the
+// extension of filename.
+func WriteResidencyFile(filename string, days []processor.ResidencyDay) error {
+	var write func(io.Writer, []processor.ResidencyDay) error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		write = WriteResidencyCsv
+	case ".json":
+		write = WriteResidencyJson
+	default:
+		return fmt.Errorf("only .csv and .json are supported")
+	}
+	return writeFile(filename, func(w io.Writer) error {
+		return write(w, days)
+	})
+}
+
+// WriteResidencyCsv writes the given days as CSV with a header row and one row per evidence.
+func WriteResidencyCsv(w io.Writer, days []processor.ResidencyDay) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"day", "countries", "country", "region", "time", "lat", "lng", "carried"}); err != nil {
+		return err
+	}
+	for _, d := range days {
+		r := newResidencyDayRecord(d)
+		for _, e := range r.Evidence {
+			err := cw.Write([]string{
+				r.Day,
+				strings.Join(r.Countries, " "),
+				e.Country,
+				e.Region,
+				e.Time,
+				strconv.FormatFloat(e.Lat, 'f', 7, 64),
+				strconv.FormatFloat(e.Lng, 'f', 7, 64),
+				strconv.FormatBool(r.Carried),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteResidencyJson writes the given days as JSON array.
+func WriteResidencyJson(w io.Writer, days []processor.ResidencyDay) error {
+	records := make([]residencyDayRecord, 0, len(days))
+	for _, d := range days {
+		records = append(records, newResidencyDayRecord(d))
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
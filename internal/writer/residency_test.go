@@ -0,0 +1,94 @@
+package writer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/panmari/locationhistory/internal/processor"
+)
+
+var residencyDays = []processor.ResidencyDay{{
+	Day:       time.Date(2024, 4, 4, 0, 0, 0, 0, time.UTC),
+	Countries: []string{"CH", "DE"},
+	Evidence: []processor.Evidence{
+		{Country: "CH", Region: "CH-ZH", Time: time.Date(2024, 4, 4, 10, 0, 0, 0, time.UTC), LatLng: s2.LatLngFromDegrees(47.5, 8.5)},
+		{Country: "DE", Region: "DE-BW", Time: time.Date(2024, 4, 4, 22, 0, 0, 0, time.UTC), LatLng: s2.LatLngFromDegrees(47.5, 7.75)},
+	},
+}, {
+	Day:       time.Date(2024, 4, 5, 0, 0, 0, 0, time.UTC),
+	Countries: []string{"DE"},
+	Evidence: []processor.Evidence{
+		{Country: "DE", Region: "DE-BW", Time: time.Date(2024, 4, 4, 22, 0, 0, 0, time.UTC), LatLng: s2.LatLngFromDegrees(47.5, 7.75)},
+	},
+	Carried: true,
+}}
+
+func TestWriteResidencyCsv(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResidencyCsv(&buf, residencyDays); err != nil {
+		t.Fatalf("WriteResidencyCsv() = %v", err)
+	}
+	want := `day,countries,country,region,time,lat,lng,carried
+2024-04-04,CH DE,CH,CH-ZH,2024-04-04T10:00:00Z,47.5000000,8.5000000,false
+2024-04-04,CH DE,DE,DE-BW,2024-04-04T22:00:00Z,47.5000000,7.7500000,false
+2024-04-05,DE,DE,DE-BW,2024-04-04T22:00:00Z,47.5000000,7.7500000,true
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("WriteResidencyCsv() diff: %v", diff)
+	}
+}
+
+func TestWriteResidencyJson(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResidencyJson(&buf, residencyDays); err != nil {
+		t.Fatalf("WriteResidencyJson() = %v", err)
+	}
+	want := `[
+  {
+    "day": "2024-04-04",
+    "countries": [
+      "CH",
+      "DE"
+    ],
+    "evidence": [
+      {
+        "country": "CH",
+        "region": "CH-ZH",
+        "time": "2024-04-04T10:00:00Z",
+        "lat": 47.5,
+        "lng": 8.5
+      },
+      {
+        "country": "DE",
+        "region": "DE-BW",
+        "time": "2024-04-04T22:00:00Z",
+        "lat": 47.5,
+        "lng": 7.75
+      }
+    ]
+  },
+  {
+    "day": "2024-04-05",
+    "countries": [
+      "DE"
+    ],
+    "evidence": [
+      {
+        "country": "DE",
+        "region": "DE-BW",
+        "time": "2024-04-04T22:00:00Z",
+        "lat": 47.5,
+        "lng": 7.75
+      }
+    ],
+    "carried": true
+  }
+]
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("WriteResidencyJson() diff: %v", diff)
+	}
+}
@@ -0,0 +1,89 @@
+package writer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/panmari/locationhistory/internal/processor"
+)
+
+// tripRecord is the representation of a processor.Trip in exported files.
+type tripRecord struct {
+	Start         string  `json:"start"`
+	End           string  `json:"end"`
+	Days          int     `json:"days"`
+	MaxDistanceKm float64 `json:"maxDistanceKm"`
+	FarthestLat   float64 `json:"farthestLat"`
+	FarthestLng   float64 `json:"farthestLng"`
+	PathLengthKm  float64 `json:"pathLengthKm"`
+}
+
+func newTripRecord(t processor.Trip) tripRecord {
+	return tripRecord{
+		Start:         t.Start.Format(time.DateOnly),
+		End:           t.End.Format(time.DateOnly),
+		Days:          t.Days(),
+		MaxDistanceKm: t.MaxDistance.Kilometers(),
+		FarthestLat:   t.Farthest.Lat.Degrees(),
+		FarthestLng:   t.Farthest.Lng.Degrees(),
+		PathLengthKm:  t.PathLength.Kilometers(),
+	}
+}
+
+// WriteTripsFile writes the given trips to a file, either as .csv or .json depending on the extension of filename.
+func WriteTripsFile(filename string, trips []processor.Trip) error {
+	var write func(io.Writer, []processor.Trip) error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		write = WriteTripsCsv
+	case ".json":
+		write = WriteTripsJson
+	default:
+		return fmt.Errorf("only .csv and .json are supported")
+	}
+	return writeFile(filename, func(w io.Writer) error {
+		return write(w, trips)
+	})
+}
+
+// WriteTripsCsv writes the given trips as CSV with a header row.
+func WriteTripsCsv(w io.Writer, trips []processor.Trip) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"start", "end", "days", "max_distance_km", "farthest_lat", "farthest_lng", "path_length_km"}); err != nil {
+		return err
+	}
+	for _, t := range trips {
+		r := newTripRecord(t)
+		err := cw.Write([]string{
+			r.Start,
+			r.End,
+			strconv.Itoa(r.Days),
+			strconv.FormatFloat(r.MaxDistanceKm, 'f', 1, 64),
+			strconv.FormatFloat(r.FarthestLat, 'f', 7, 64),
+			strconv.FormatFloat(r.FarthestLng, 'f', 7, 64),
+			strconv.FormatFloat(r.PathLengthKm, 'f', 1, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteTripsJson writes the given trips as JSON array.
+func WriteTripsJson(w io.Writer, trips []processor.Trip) error {
+	records := make([]tripRecord, 0, len(trips))
+	for _, t := range trips {
+		records = append(records, newTripRecord(t))
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
@@ -0,0 +1,55 @@
+package writer
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/golang/geo/s2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-units/unit"
+	"github.com/panmari/locationhistory/internal/processor"
+)
+
+var trips = []processor.Trip{{
+	Start:       time.Date(2014, 4, 2, 0, 0, 0, 0, time.UTC),
+	End:         time.Date(2014, 4, 3, 0, 0, 0, 0, time.UTC),
+	MaxDistance: 222.39 * unit.Kilometer,
+	Farthest:    s2.LatLngFromDegrees(49, 8),
+	PathLength:  333.585 * unit.Kilometer,
+}}
+
+func TestWriteTripsCsv(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTripsCsv(&buf, trips); err != nil {
+		t.Fatalf("WriteTripsCsv() = %v", err)
+	}
+	want := `start,end,days,max_distance_km,farthest_lat,farthest_lng,path_length_km
+2014-04-02,2014-04-03,2,222.4,49.0000000,8.0000000,333.6
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("WriteTripsCsv() diff: %v", diff)
+	}
+}
+
+func TestWriteTripsJson(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteTripsJson(&buf, trips); err != nil {
+		t.Fatalf("WriteTripsJson() = %v", err)
+	}
+	want := `[
+  {
+    "start": "2014-04-02",
+    "end": "2014-04-03",
+    "days": 2,
+    "maxDistanceKm": 222.39,
+    "farthestLat": 49,
+    "farthestLng": 8,
+    "pathLengthKm": 333.585
+  }
+]
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("WriteTripsJson() diff: %v", diff)
+	}
+}
@@ -0,0 +1,49 @@
+// Package writer exports locations to formats understood by other GIS tools.
+package writer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+var writers = map[string]func(io.Writer, []reader.Location) error{
+	".gpx":     WriteGpx,
+	".kml":     WriteKml,
+	".geojson": WriteGeoJson,
+	".csv":     WriteCsv,
+	".json":    WriteJson,
+}
+
+// WriteFile writes the given locations to a file. The format is chosen based on the extension of filename,
+// one of .gpx, .kml, .geojson, .csv or .json.
+func WriteFile(filename string, locations []reader.Location) error {
+	write, ok := writers[strings.ToLower(filepath.Ext(filename))]
+	if !ok {
+		return fmt.Errorf("only .gpx, .kml, .geojson, .csv and .json are supported")
+	}
+	return writeFile(filename, func(w io.Writer) error {
+		return write(w, locations)
+	})
+}
+
+// writeFile creates the given file and writes to it using the given function.
+func writeFile(filename string, write func(io.Writer) error) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func latLng(loc reader.Location) (lat, lng float64) {
+	return float64(loc.LatitudeE7) / 1e7, float64(loc.LongitudeE7) / 1e7
+}
@@ -0,0 +1,82 @@
+package writer
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/panmari/locationhistory/internal/reader"
+)
+
+var locations = []reader.Location{
+	{Timestamp: "2020-05-01T08:00:00Z", LatitudeE7: 469480000, LongitudeE7: 74474000, Altitude: 540},
+	{Timestamp: "2020-05-01T09:00:00Z", LatitudeE7: 473769000, LongitudeE7: 85417000},
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		write  func(io.Writer, []reader.Location) error
+		decode func(io.Reader, func(reader.Location) error) error
+		want   []reader.Location
+	}{
+		{
+			name:   "GPX",
+			write:  WriteGpx,
+			decode: reader.DecodeGpxFunc,
+			want: []reader.Location{
+				{Timestamp: "2020-05-01T08:00:00Z", LatitudeE7: 469480000, LongitudeE7: 74474000, Altitude: 540, Source: "GPS"},
+				{Timestamp: "2020-05-01T09:00:00Z", LatitudeE7: 473769000, LongitudeE7: 85417000, Source: "GPS"},
+			},
+		}, {
+			name:   "KML",
+			write:  WriteKml,
+			decode: reader.DecodeKmlFunc,
+			want:   locations,
+		}, {
+			name:   "JSON",
+			write:  WriteJson,
+			decode: reader.DecodeJsonFunc,
+			want:   locations,
+		}, {
+			name:   "GeoJSON",
+			write:  WriteGeoJson,
+			decode: reader.DecodeGeoJsonFunc,
+			// Altitude is not written.
+			want: []reader.Location{
+				{Timestamp: "2020-05-01T08:00:00Z", LatitudeE7: 469480000, LongitudeE7: 74474000},
+				{Timestamp: "2020-05-01T09:00:00Z", LatitudeE7: 473769000, LongitudeE7: 85417000},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tc.write(&buf, locations); err != nil {
+				t.Fatalf("write() = %v", err)
+			}
+			var got []reader.Location
+			err := tc.decode(&buf, func(l reader.Location) error {
+				got = append(got, l)
+				return nil
+			})
+			if diff := cmp.Diff(got, tc.want); err != nil || diff != "" {
+				t.Errorf("decode(write()) = %v, %v, want %v. Diff: %v", got, err, tc.want, diff)
+			}
+		})
+	}
+}
+
+func TestWriteCsv(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCsv(&buf, locations); err != nil {
+		t.Fatalf("WriteCsv() = %v", err)
+	}
+	want := `timestamp,latitude,longitude,accuracy,altitude,source
+2020-05-01T08:00:00Z,46.9480000,7.4474000,0,540,
+2020-05-01T09:00:00Z,47.3769000,8.5417000,0,0,
+`
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("WriteCsv() diff: %v", diff)
+	}
+}